@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dongri/emv-qrcode/emv/mpm"
+	"github.com/dongri/emv-qrcode/redact"
+)
+
+func runDiff(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	policy := fs.String("redact", "first6last4", "card data redaction: first6last4, mask, hash (keyed by $EMVQR_REDACT_KEY) or none")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	p, err := redact.ParsePolicy(*policy)
+	if err != nil {
+		fmt.Fprintf(stderr, "emvqr diff: %v\n", err)
+		return 2
+	}
+	redactor := redact.Redactor{Policy: p, Key: []byte(os.Getenv("EMVQR_REDACT_KEY"))}
+	var qrs [2]*mpm.EMVQR
+	for i, payload := range fs.Args() {
+		qr, err := mpm.ParseEMVQR(strings.TrimSpace(payload))
+		if err != nil {
+			fmt.Fprintf(stderr, "emvqr diff: payload %d: %v\n", i+1, err)
+			return 2
+		}
+		qrs[i] = qr
+	}
+	changes := mpm.Diff(qrs[0], qrs[1])
+	for _, c := range changes {
+		fmt.Fprintln(stdout, c.StringWith(redactor))
+	}
+	if len(changes) > 0 {
+		return 1
+	}
+	return 0
+}
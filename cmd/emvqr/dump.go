@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dongri/emv-qrcode/emv/cpm"
+	"github.com/dongri/emv-qrcode/emv/mpm"
+	"github.com/dongri/emv-qrcode/redact"
+)
+
+func runDump(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("dump", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	color := fs.String("color", "auto", "colour output: auto, always or never")
+	policy := fs.String("redact", "first6last4", "card data redaction: first6last4, mask, hash (keyed by $EMVQR_REDACT_KEY) or none")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	var useColor bool
+	switch *color {
+	case "auto":
+		useColor = isTerminal(stdout) && os.Getenv("NO_COLOR") == ""
+	case "always":
+		useColor = true
+	case "never":
+	default:
+		fmt.Fprintf(stderr, "emvqr dump: -color should be auto, always or never, color: %s\n", *color)
+		return 2
+	}
+	p, err := redact.ParsePolicy(*policy)
+	if err != nil {
+		fmt.Fprintf(stderr, "emvqr dump: %v\n", err)
+		return 2
+	}
+	redactor := redact.Redactor{Policy: p, Key: []byte(os.Getenv("EMVQR_REDACT_KEY"))}
+	payload := strings.TrimSpace(fs.Arg(0))
+	if isCPM(payload) {
+		err = cpm.Dump(stdout, payload, cpm.DumpOptions{Color: useColor, Redact: &redactor})
+	} else {
+		err = mpm.Dump(stdout, payload, mpm.DumpOptions{Color: useColor, Redact: &redactor})
+	}
+	if e, ok := err.(*mpm.ParserError); ok {
+		fmt.Fprintf(stderr, "emvqr dump:\n%s\n", e.CaretWith(redactor))
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "emvqr dump: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+// isCPM reports whether payload is a CPM payload: base64 starting with the
+// Payload Format Indicator (85), where MPM payloads start with ID 00.
+func isCPM(payload string) bool {
+	return strings.HasPrefix(payload, "hQ")
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,41 @@
+// Command emvqr inspects EMV QR code payloads.
+//
+//	emvqr diff [-redact first6last4|mask|hash|none] <payload> <payload>
+//	emvqr dump [-color auto|always|never] [-redact first6last4|mask|hash|none] <payload>
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `usage: emvqr <command> [arguments]
+
+commands:
+  diff [-redact first6last4|mask|hash|none] <payload> <payload>
+                            report the data objects added, removed or changed
+  dump [-color auto|always|never] [-redact first6last4|mask|hash|none] <payload>
+                            print the data objects of an MPM or CPM payload with their names
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes a command and returns the exit status:
+// 0 on success, 1 when the command found differences and 2 on errors.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	switch args[0] {
+	case "diff":
+		return runDiff(args[1:], stdout, stderr)
+	case "dump":
+		return runDump(args[1:], stdout, stderr)
+	}
+	fmt.Fprintf(stderr, "emvqr: unknown command %q\n%s", args[0], usage)
+	return 2
+}
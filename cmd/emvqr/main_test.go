@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+const payload = "00020101021229300012D156000000000510A93FO3230Q31280012D15600000001030812345678520441115802CN5914BEST TRANSPORT6007BEIJING6304ABCD"
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantStatus int
+		wantStdout string
+	}{
+		{
+			name:       "no command",
+			args:       nil,
+			wantStatus: 2,
+		},
+		{
+			name:       "unknown command",
+			args:       []string{"foo"},
+			wantStatus: 2,
+		},
+		{
+			name:       "diff same",
+			args:       []string{"diff", payload, payload},
+			wantStatus: 0,
+		},
+		{
+			name:       "diff changed",
+			args:       []string{"diff", payload, payload[:len(payload)-4] + "FFFF"},
+			wantStatus: 1,
+			wantStdout: "CRC differs: \"ABCD\" -> \"FFFF\"\n",
+		},
+		{
+			name:       "dump mpm",
+			args:       []string{"dump", "000201"},
+			wantStatus: 0,
+			wantStdout: "00 02 Payload Format Indicator: \"01\"\n",
+		},
+		{
+			name:       "dump cpm",
+			args:       []string{"dump", "-color", "never", "hQVDUFYwMQ=="},
+			wantStatus: 0,
+			wantStdout: "0000 85 05 Payload Format Indicator: 43 50 56 30 31 |CPV01|\n",
+		},
+		{
+			name:       "dump redacts by default",
+			args:       []string{"dump", "00020102164111111111111111"},
+			wantStatus: 0,
+			wantStdout: "00 02 Payload Format Indicator: \"01\"\n02 16 Merchant Account Information: \"411111******1111\" (Visa)\n",
+		},
+		{
+			name:       "dump without redaction",
+			args:       []string{"dump", "-redact", "none", "00020102164111111111111111"},
+			wantStatus: 0,
+			wantStdout: "00 02 Payload Format Indicator: \"01\"\n02 16 Merchant Account Information: \"4111111111111111\" (Visa)\n",
+		},
+		{
+			name:       "dump bad redact",
+			args:       []string{"dump", "-redact", "pan", "000201"},
+			wantStatus: 2,
+		},
+		{
+			name:       "dump bad color",
+			args:       []string{"dump", "-color", "blue", "000201"},
+			wantStatus: 2,
+		},
+		{
+			name:       "dump broken payload",
+			args:       []string{"dump", "0002"},
+			wantStatus: 2,
+		},
+		{
+			name:       "diff redacts by default",
+			args:       []string{"diff", "00020102164111111111111111", "00020102165555555555554444"},
+			wantStatus: 1,
+			wantStdout: "02 changed: \"411111******1111\" -> \"555555******4444\"\n",
+		},
+		{
+			name:       "diff without redaction",
+			args:       []string{"diff", "-redact", "none", "00020102164111111111111111", "00020102165555555555554444"},
+			wantStatus: 1,
+			wantStdout: "02 changed: \"4111111111111111\" -> \"5555555555554444\"\n",
+		},
+		{
+			name:       "diff bad redact",
+			args:       []string{"diff", "-redact", "pan", payload, payload},
+			wantStatus: 2,
+		},
+		{
+			name:       "diff broken payload",
+			args:       []string{"diff", payload, "0002"},
+			wantStatus: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if got := run(tt.args, &stdout, &stderr); got != tt.wantStatus {
+				t.Errorf("run() = %v, want %v, stderr: %s", got, tt.wantStatus, stderr.String())
+			}
+			if got := stdout.String(); got != tt.wantStdout {
+				t.Errorf("run() stdout = %q, want %q", got, tt.wantStdout)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Package crypto computes the Application Cryptogram (9F26) of CPM payloads
+// with EMV common session keys, from issuer master keys held by a KeyProvider.
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Algorithm is the block cipher of a master key.
+type Algorithm int
+
+// const ...
+const (
+	Algorithm3DES Algorithm = iota + 1 // double length TDEA, ISO/IEC 9797-1 MAC Algorithm 3
+	AlgorithmAES                       // AES, CMAC
+)
+
+// String ...
+func (a Algorithm) String() string {
+	switch a {
+	case Algorithm3DES:
+		return "3DES"
+	case AlgorithmAES:
+		return "AES"
+	}
+	return fmt.Sprintf("Algorithm(%d)", int(a))
+}
+
+// errors ...
+var (
+	ErrKeyNotFound      = errors.New("crypto: key not found")
+	ErrUnknownAlgorithm = errors.New("crypto: unknown algorithm")
+)
+
+// MasterKey is an ICC Master Key for Application Cryptograms (MK-AC).
+type MasterKey struct {
+	Algorithm Algorithm
+	Key       []byte
+}
+
+// Validate ...
+func (k MasterKey) Validate() error {
+	switch k.Algorithm {
+	case Algorithm3DES:
+		if len(k.Key) != 16 {
+			return fmt.Errorf("crypto: 3DES master key should be 16 bytes, length: %d", len(k.Key))
+		}
+	case AlgorithmAES:
+		if len(k.Key) != 16 && len(k.Key) != 24 && len(k.Key) != 32 {
+			return fmt.Errorf("crypto: AES master key should be 16, 24 or 32 bytes, length: %d", len(k.Key))
+		}
+	default:
+		return ErrUnknownAlgorithm
+	}
+	return nil
+}
+
+// KeyID identifies the master key of a card application.
+type KeyID struct {
+	PAN               string // digits, without F padding
+	PANSequenceNumber string // 5F34 in hex, "" if the card has none
+}
+
+// KeyProvider looks up master keys, such as a HSM or the SoftwareKeyStore.
+// It returns ErrKeyNotFound for unknown IDs.
+type KeyProvider interface {
+	MasterKey(id KeyID) (MasterKey, error)
+}
+
+// SessionKey derives the EMV common session key (EMV Book 2, A1.3) of the
+// Application Transaction Counter atc from mk.
+func SessionKey(mk MasterKey, atc []byte) ([]byte, error) {
+	if err := mk.Validate(); err != nil {
+		return nil, err
+	}
+	if len(atc) != 2 {
+		return nil, fmt.Errorf("crypto: ATC should be 2 bytes, length: %d", len(atc))
+	}
+	block, err := mk.block()
+	if err != nil {
+		return nil, err
+	}
+	n := block.BlockSize()
+	f1 := make([]byte, n)
+	copy(f1, atc)
+	f2 := make([]byte, n)
+	copy(f2, atc)
+	f1[2] = 0xF0
+	f2[2] = 0x0F
+	sk := make([]byte, 2*n)
+	block.Encrypt(sk[:n], f1)
+	block.Encrypt(sk[n:], f2)
+	return sk[:len(mk.Key)], nil
+}
+
+func (k MasterKey) block() (cipher.Block, error) {
+	return newBlock(k.Algorithm, k.Key)
+}
+
+func newBlock(algorithm Algorithm, key []byte) (cipher.Block, error) {
+	switch algorithm {
+	case Algorithm3DES:
+		if len(key) != 16 {
+			return nil, fmt.Errorf("crypto: 3DES key should be 16 bytes, length: %d", len(key))
+		}
+		// K1 || K2 || K1
+		return des.NewTripleDESCipher(append(append([]byte{}, key...), key[:8]...))
+	case AlgorithmAES:
+		return aes.NewCipher(key)
+	}
+	return nil, ErrUnknownAlgorithm
+}
+
+// ApplicationCryptogram computes the 8 byte Application Cryptogram over data
+// with the session key of atc.
+func ApplicationCryptogram(mk MasterKey, atc, data []byte) ([]byte, error) {
+	sk, err := SessionKey(mk, atc)
+	if err != nil {
+		return nil, err
+	}
+	if mk.Algorithm == Algorithm3DES {
+		return MAC3(sk, data)
+	}
+	mac, err := CMAC(sk, data)
+	if err != nil {
+		return nil, err
+	}
+	return mac[:8], nil
+}
+
+func decodeHex(name, value string) ([]byte, error) {
+	b, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("crypto: %s should be even-length hex, %s: %s", name, name, value)
+	}
+	return b, nil
+}
+
+func encodeHex(b []byte) string {
+	return strings.ToUpper(hex.EncodeToString(b))
+}
@@ -0,0 +1,354 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/dongri/emv-qrcode/emv/cpm"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestCMAC(t *testing.T) {
+	// RFC 4493, 4. Test Vectors
+	key := mustHex("2b7e151628aed2a6abf7158809cf4f3c")
+	message := "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17ad2b417be66c3710"
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "empty",
+			data: "",
+			want: "bb1d6929e95937287fa37d129b756746",
+		},
+		{
+			name: "16 bytes",
+			data: message[:32],
+			want: "070a16b46b4d4144f79bdd9dd04a287c",
+		},
+		{
+			name: "40 bytes",
+			data: message[:80],
+			want: "dfa66747de9ae63030ca32611497c827",
+		},
+		{
+			name: "64 bytes",
+			data: message,
+			want: "51f0bebf7e3b9d92fc49741779363cfe",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CMAC(key, mustHex(tt.data))
+			if err != nil {
+				t.Fatalf("CMAC() error = %v", err)
+			}
+			if hex.EncodeToString(got) != tt.want {
+				t.Errorf("CMAC() = %x, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMAC3(t *testing.T) {
+	got, err := MAC3(mustHex("0123456789ABCDEFFEDCBA9876543210"), mustHex("000102030405060708090A0B"))
+	if err != nil {
+		t.Fatalf("MAC3() error = %v", err)
+	}
+	if want := "6FD4C86801BD51FD"; encodeHex(got) != want {
+		t.Errorf("MAC3() = %X, want %s", got, want)
+	}
+	if _, err := MAC3(make([]byte, 8), nil); err == nil {
+		t.Errorf("MAC3() with a single length key should fail")
+	}
+}
+
+func TestSessionKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		mk      MasterKey
+		atc     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "3DES",
+			mk:   MasterKey{Algorithm3DES, mustHex("0123456789ABCDEFFEDCBA9876543210")},
+			atc:  "0001",
+			want: "848C35717F66D40944F9286FF19ABFFD",
+		},
+		{
+			name: "AES",
+			mk:   MasterKey{AlgorithmAES, mustHex("000102030405060708090A0B0C0D0E0F")},
+			atc:  "0001",
+			want: "3A9979458D82F193321BA11527DE6C4C",
+		},
+		{
+			name:    "short ATC",
+			mk:      MasterKey{AlgorithmAES, mustHex("000102030405060708090A0B0C0D0E0F")},
+			atc:     "01",
+			wantErr: true,
+		},
+		{
+			name:    "short 3DES key",
+			mk:      MasterKey{Algorithm3DES, mustHex("0123456789ABCDEF")},
+			atc:     "0001",
+			wantErr: true,
+		},
+		{
+			name:    "no algorithm",
+			mk:      MasterKey{Key: mustHex("000102030405060708090A0B0C0D0E0F")},
+			atc:     "0001",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SessionKey(tt.mk, mustHex(tt.atc))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SessionKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if encodeHex(got) != tt.want {
+				t.Errorf("SessionKey() = %X, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDOL(t *testing.T) {
+	dol, err := ParseDOL("9F02069F1A02950582029F3604")
+	if err != nil {
+		t.Fatalf("ParseDOL() error = %v", err)
+	}
+	want := DOL{{"9F02", 6}, {"9F1A", 2}, {"95", 5}, {"82", 2}, {"9F36", 4}}
+	if !reflect.DeepEqual(dol, want) {
+		t.Errorf("ParseDOL() = %v, want %v", dol, want)
+	}
+	if got := dol.String(); got != "9F02069F1A02950582029F3604" {
+		t.Errorf("DOL.String() = %v", got)
+	}
+	got := dol.Build(map[string][]byte{
+		"9F02": mustHex("1000"),   // numeric, padded on the left
+		"9F1A": mustHex("010392"), // numeric, truncated on the left
+		"82":   mustHex("1980AA"), // truncated on the right
+		"9F36": mustHex("0001"),   // padded on the right
+	})
+	if want := "000000001000" + "0392" + "0000000000" + "1980" + "00010000"; encodeHex(got) != want {
+		t.Errorf("DOL.Build() = %X, want %s", got, want)
+	}
+	if _, err := ParseDOL("9F02"); err == nil {
+		t.Errorf("ParseDOL() of a truncated DOL should fail")
+	}
+}
+
+func newSignedEMVQR() *cpm.EMVQR {
+	qr := new(cpm.EMVQR)
+	qr.DataPayloadFormatIndicator = cpm.PayloadFormatIndicatorCPV01
+	at := new(cpm.ApplicationTemplate)
+	at.DataApplicationDefinitionFileName = "A0000000555555"
+	qr.ApplicationTemplates = append(qr.ApplicationTemplates, *at)
+	cdt := new(cpm.CommonDataTemplate)
+	cdt.DataApplicationPAN = "1234567890123458"
+	cdtt := new(cpm.CommonDataTransparentTemplate)
+	cdtt.DataIssuerApplicationData = "06010A03000000"
+	cdtt.DataApplicationTransactionCounter = "0001"
+	cdtt.DataUnpredictableNumber = "6D58EF13"
+	cdt.CommonDataTransparentTemplates = append(cdt.CommonDataTransparentTemplates, *cdtt)
+	qr.CommonDataTemplates = append(qr.CommonDataTemplates, *cdt)
+	return qr
+}
+
+func TestSign(t *testing.T) {
+	tx := Transaction{
+		AmountAuthorised:        "000000001000",
+		TerminalCountryCode:     "0392",
+		TransactionCurrencyCode: "0392",
+		TransactionDate:         "261018",
+		TransactionType:         "00",
+	}
+	// DefaultCDOL followed by the Issuer Application Data
+	data := mustHex("000000001000" + "000000000000" + "0392" + "0000000000" + "0392" + "261018" + "00" + "6D58EF13" + "0000" + "0001" + "06010A03000000")
+	id := KeyID{PAN: "1234567890123458"}
+	tests := []struct {
+		name string
+		mk   MasterKey
+	}{
+		{
+			name: "3DES",
+			mk:   MasterKey{Algorithm3DES, mustHex("0123456789ABCDEFFEDCBA9876543210")},
+		},
+		{
+			name: "AES",
+			mk:   MasterKey{AlgorithmAES, mustHex("000102030405060708090A0B0C0D0E0F")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := NewSoftwareKeyStore()
+			if err := keys.Add(id, tt.mk); err != nil {
+				t.Fatalf("SoftwareKeyStore.Add() error = %v", err)
+			}
+			qr := newSignedEMVQR()
+			if err := Sign(keys, tx, qr); err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			want, err := ApplicationCryptogram(tt.mk, mustHex("0001"), data)
+			if err != nil {
+				t.Fatalf("ApplicationCryptogram() error = %v", err)
+			}
+			got := qr.CommonDataTemplates[0].CommonDataTransparentTemplates[0].DataApplicationCryptogram
+			if got != encodeHex(want) {
+				t.Errorf("Sign() 9F26 = %s, want %X", got, want)
+			}
+			if _, err := cpm.Encode(qr); err != nil {
+				t.Errorf("Encode() of the signed payload error = %v", err)
+			}
+		})
+	}
+}
+
+func TestSign_Errors(t *testing.T) {
+	keys := NewSoftwareKeyStore()
+	if err := Sign(keys, Transaction{}, newSignedEMVQR()); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Sign() error = %v, want %v", err, ErrKeyNotFound)
+	}
+	if err := keys.Add(KeyID{PAN: "1234567890123458"}, MasterKey{AlgorithmAES, make([]byte, 16)}); err != nil {
+		t.Fatalf("SoftwareKeyStore.Add() error = %v", err)
+	}
+	qr := newSignedEMVQR()
+	qr.CommonDataTemplates[0].CommonDataTransparentTemplates[0].DataApplicationTransactionCounter = ""
+	if err := Sign(keys, Transaction{}, qr); err == nil {
+		t.Errorf("Sign() without an ATC should fail")
+	}
+	qr = newSignedEMVQR()
+	qr.CommonDataTemplates[0].CommonDataTransparentTemplates = nil
+	if err := Sign(keys, Transaction{}, qr); err == nil {
+		t.Errorf("Sign() without a transparent template should fail")
+	}
+	if err := Sign(keys, Transaction{AmountAuthorised: "10.00"}, newSignedEMVQR()); err == nil {
+		t.Errorf("Sign() with a bad amount should fail")
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	id := KeyID{PAN: "1234567890123458"}
+	keys := NewSoftwareKeyStore()
+	if err := keys.Add(id, MasterKey{Algorithm3DES, mustHex("0123456789ABCDEFFEDCBA9876543210")}); err != nil {
+		t.Fatalf("SoftwareKeyStore.Add() error = %v", err)
+	}
+	tx := Transaction{AmountAuthorised: "000000001000", TransactionCurrencyCode: "0392"}
+	signed := func(atc string) *cpm.EMVQR {
+		qr := newSignedEMVQR()
+		qr.CommonDataTemplates[0].CommonDataTransparentTemplates[0].DataApplicationTransactionCounter = atc
+		if err := Sign(keys, tx, qr); err != nil {
+			t.Fatalf("Sign() error = %v", err)
+		}
+		// through the payload, as an issuer would see it
+		payload, err := cpm.Encode(qr)
+		if err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+		decoded, err := cpm.Decode(payload)
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		return decoded
+	}
+	v := &Verifier{Keys: keys, ATCs: NewMemoryATCTracker()}
+	tests := []struct {
+		name   string
+		qr     *cpm.EMVQR
+		tx     Transaction
+		keys   KeyProvider
+		want   Status
+		wantAT int
+	}{
+		{
+			name:   "valid",
+			qr:     signed("0002"),
+			tx:     tx,
+			want:   StatusValid,
+			wantAT: 2,
+		},
+		{
+			name:   "replay",
+			qr:     signed("0002"),
+			tx:     tx,
+			want:   StatusATCReplay,
+			wantAT: 2,
+		},
+		{
+			name:   "lower ATC",
+			qr:     signed("0001"),
+			tx:     tx,
+			want:   StatusATCReplay,
+			wantAT: 1,
+		},
+		{
+			name:   "other amount",
+			qr:     signed("0003"),
+			tx:     Transaction{AmountAuthorised: "000000002000", TransactionCurrencyCode: "0392"},
+			want:   StatusInvalid,
+			wantAT: 3,
+		},
+		{
+			// not used up by the invalid cryptogram above
+			name:   "next ATC",
+			qr:     signed("0003"),
+			tx:     tx,
+			want:   StatusValid,
+			wantAT: 3,
+		},
+		{
+			name:   "key missing",
+			qr:     signed("0004"),
+			tx:     tx,
+			keys:   NewSoftwareKeyStore(),
+			want:   StatusKeyMissing,
+			wantAT: 4,
+		},
+		{
+			name: "no cryptogram",
+			qr:   newSignedEMVQR(),
+			tx:   tx,
+			want: StatusMalformed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := *v
+			if tt.keys != nil {
+				v.Keys = tt.keys
+			}
+			results, err := v.Verify(tt.tx, tt.qr)
+			if err != nil {
+				t.Fatalf("Verifier.Verify() error = %v", err)
+			}
+			if len(results) != 1 {
+				t.Fatalf("Verifier.Verify() = %v, want 1 result", results)
+			}
+			r := results[0]
+			if r.Status != tt.want || r.Path != "62[0] > 64[0]" {
+				t.Errorf("Verifier.Verify() = %v at %s (%v), want %v", r.Status, r.Path, r.Err, tt.want)
+			}
+			if tt.want != StatusMalformed && (r.ATC != tt.wantAT || r.KeyID != id) {
+				t.Errorf("Verifier.Verify() = ATC %d of %v, want %d of %v", r.ATC, r.KeyID, tt.wantAT, id)
+			}
+		})
+	}
+	qr := newSignedEMVQR()
+	qr.CommonDataTemplates[0].CommonDataTransparentTemplates = nil
+	if _, err := v.Verify(tx, qr); err == nil {
+		t.Errorf("Verifier.Verify() without a transparent template should fail")
+	}
+}
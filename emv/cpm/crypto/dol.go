@@ -0,0 +1,108 @@
+package crypto
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Terminal data objects of the CDOL.
+const (
+	TagAmountAuthorised            = "9F02"
+	TagAmountOther                 = "9F03"
+	TagTerminalCountryCode         = "9F1A"
+	TagTerminalVerificationResults = "95"
+	TagTransactionCurrencyCode     = "5F2A"
+	TagTransactionDate             = "9A"
+	TagTransactionType             = "9C"
+)
+
+// DOLEntry is a tag and the length of its value in a Data Object List.
+type DOLEntry struct {
+	Tag    string
+	Length int
+}
+
+// DOL is a Data Object List, such as the CDOL of a card, which lists the
+// values the Application Cryptogram is computed over.
+type DOL []DOLEntry
+
+// DefaultCDOL is the minimum data set recommended for Application Cryptograms
+// in EMV Book 2, 8.1.1.
+var DefaultCDOL = DOL{
+	{TagAmountAuthorised, 6},
+	{TagAmountOther, 6},
+	{TagTerminalCountryCode, 2},
+	{TagTerminalVerificationResults, 5},
+	{TagTransactionCurrencyCode, 2},
+	{TagTransactionDate, 3},
+	{TagTransactionType, 1},
+	{"9F37", 4}, // Unpredictable Number
+	{"82", 2},   // Application Interchange Profile
+	{"9F36", 2}, // Application Transaction Counter
+}
+
+// numericTags are the tags of the DefaultCDOL with values in format n, which
+// are right justified.
+var numericTags = map[string]bool{
+	TagAmountAuthorised:        true,
+	TagAmountOther:             true,
+	TagTerminalCountryCode:     true,
+	TagTransactionCurrencyCode: true,
+	TagTransactionDate:         true,
+	TagTransactionType:         true,
+}
+
+// ParseDOL parses a Data Object List from hex, such as "9F02069F0306".
+func ParseDOL(value string) (DOL, error) {
+	data, err := decodeHex("DOL", value)
+	if err != nil {
+		return nil, err
+	}
+	var dol DOL
+	for pos := 0; pos < len(data); {
+		start := pos
+		if data[pos]&0x1F == 0x1F {
+			pos++
+			for pos < len(data) && data[pos]&0x80 == 0x80 {
+				pos++
+			}
+		}
+		pos++
+		if pos >= len(data) {
+			return nil, fmt.Errorf("crypto: DOL entry is truncated, offset: %d", start)
+		}
+		dol = append(dol, DOLEntry{Tag: encodeHex(data[start:pos]), Length: int(data[pos])})
+		pos++
+	}
+	return dol, nil
+}
+
+// String returns the Data Object List in hex.
+func (d DOL) String() string {
+	var sb strings.Builder
+	for _, e := range d {
+		sb.WriteString(fmt.Sprintf("%s%02X", strings.ToUpper(e.Tag), e.Length))
+	}
+	return sb.String()
+}
+
+// Build concatenates the values of the Data Object List. As in EMV Book 3,
+// 5.4, missing values are zeros, numeric values are padded and truncated on
+// the left and the others on the right.
+func (d DOL) Build(values map[string][]byte) []byte {
+	var data []byte
+	for _, e := range d {
+		v := make([]byte, e.Length)
+		value := values[strings.ToUpper(e.Tag)]
+		if numericTags[strings.ToUpper(e.Tag)] {
+			if len(value) > e.Length {
+				value = value[len(value)-e.Length:]
+			}
+			copy(v[e.Length-len(value):], value)
+		} else {
+			copy(v, value)
+		}
+		data = append(data, v...)
+	}
+	return data
+}
@@ -0,0 +1,102 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"fmt"
+)
+
+// MAC3 computes the ISO/IEC 9797-1 MAC Algorithm 3 of data with the double
+// length DES key, with padding method 2 (80 followed by 00).
+func MAC3(key, data []byte) ([]byte, error) {
+	if len(key) != 16 {
+		return nil, fmt.Errorf("crypto: MAC3 key should be 16 bytes, length: %d", len(key))
+	}
+	k1, err := des.NewCipher(key[:8])
+	if err != nil {
+		return nil, err
+	}
+	k2, err := des.NewCipher(key[8:])
+	if err != nil {
+		return nil, err
+	}
+	padded := append(append([]byte{}, data...), 0x80)
+	for len(padded)%des.BlockSize != 0 {
+		padded = append(padded, 0x00)
+	}
+	mac := make([]byte, des.BlockSize)
+	for i := 0; i < len(padded); i += des.BlockSize {
+		xor(mac, padded[i:i+des.BlockSize])
+		k1.Encrypt(mac, mac)
+	}
+	k2.Decrypt(mac, mac)
+	k1.Encrypt(mac, mac)
+	return mac, nil
+}
+
+// CMAC computes the AES-CMAC (NIST SP 800-38B, RFC 4493) of data.
+func CMAC(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cmac(block, data), nil
+}
+
+func cmac(block cipher.Block, data []byte) []byte {
+	n := block.BlockSize()
+	// subkeys
+	k1 := make([]byte, n)
+	block.Encrypt(k1, k1)
+	k1 = shift(k1)
+	k2 := shift(k1)
+
+	last := make([]byte, n)
+	blocks := (len(data) + n - 1) / n
+	if blocks > 0 && len(data)%n == 0 {
+		copy(last, data[(blocks-1)*n:])
+		xor(last, k1)
+	} else {
+		if blocks == 0 {
+			blocks = 1
+		}
+		rest := data[(blocks-1)*n:]
+		copy(last, rest)
+		last[len(rest)] = 0x80
+		xor(last, k2)
+	}
+	mac := make([]byte, n)
+	for i := 0; i < blocks-1; i++ {
+		xor(mac, data[i*n:(i+1)*n])
+		block.Encrypt(mac, mac)
+	}
+	xor(mac, last)
+	block.Encrypt(mac, mac)
+	return mac
+}
+
+// shift returns b shifted left by one bit, xored with the constant of the
+// block size if the carry is set.
+func shift(b []byte) []byte {
+	r := make([]byte, len(b))
+	var carry byte
+	for i := len(b) - 1; i >= 0; i-- {
+		r[i] = b[i]<<1 | carry
+		carry = b[i] >> 7
+	}
+	if carry == 1 {
+		if len(b) == 16 {
+			r[len(r)-1] ^= 0x87
+		} else {
+			r[len(r)-1] ^= 0x1B
+		}
+	}
+	return r
+}
+
+func xor(dst, src []byte) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
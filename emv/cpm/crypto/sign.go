@@ -0,0 +1,193 @@
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dongri/emv-qrcode/emv/cpm"
+)
+
+// Transaction is the terminal data of the DefaultCDOL, in hex as the values
+// of cpm. Empty values are zeros.
+type Transaction struct {
+	AmountAuthorised            string // 9F02, n 12
+	AmountOther                 string // 9F03, n 12
+	TerminalCountryCode         string // 9F1A, n 3
+	TerminalVerificationResults string // 95, b 5
+	TransactionCurrencyCode     string // 5F2A, n 3
+	TransactionDate             string // 9A, n 6 (YYMMDD)
+	TransactionType             string // 9C, n 2
+}
+
+func (tx Transaction) values() (map[string][]byte, error) {
+	values := make(map[string][]byte)
+	for _, v := range []struct {
+		tag, name, value string
+	}{
+		{TagAmountAuthorised, "AmountAuthorised", tx.AmountAuthorised},
+		{TagAmountOther, "AmountOther", tx.AmountOther},
+		{TagTerminalCountryCode, "TerminalCountryCode", tx.TerminalCountryCode},
+		{TagTerminalVerificationResults, "TerminalVerificationResults", tx.TerminalVerificationResults},
+		{TagTransactionCurrencyCode, "TransactionCurrencyCode", tx.TransactionCurrencyCode},
+		{TagTransactionDate, "TransactionDate", tx.TransactionDate},
+		{TagTransactionType, "TransactionType", tx.TransactionType},
+	} {
+		b, err := decodeHex(v.name, v.value)
+		if err != nil {
+			return nil, err
+		}
+		values[v.tag] = b
+	}
+	return values, nil
+}
+
+// cardData is the card data of a cryptogram, from a transparent template and
+// the templates enclosing it.
+type cardData struct {
+	id     KeyID
+	values map[string][]byte
+}
+
+func newCardData(templates []*cpm.BERTLV) (*cardData, error) {
+	c := &cardData{values: make(map[string][]byte)}
+	for _, t := range templates {
+		if t.DataApplicationPAN != "" {
+			c.id.PAN = strings.TrimRight(strings.ToUpper(t.DataApplicationPAN), "F")
+		} else if c.id.PAN == "" && t.DataTrack2EquivalentData != "" {
+			track2, err := t.Track2()
+			if err != nil {
+				return nil, err
+			}
+			c.id.PAN = track2.PAN
+		}
+		if t.DataPANSequenceNumber != "" {
+			c.id.PANSequenceNumber = strings.ToUpper(t.DataPANSequenceNumber)
+		}
+		for _, v := range []struct {
+			tag, value string
+		}{
+			{cpm.TagApplicationInterchangeProfile, t.DataApplicationInterchangeProfile},
+			{cpm.TagApplicationTransactionCounter, t.DataApplicationTransactionCounter},
+			{cpm.TagUnpredictableNumber, t.DataUnpredictableNumber},
+			{cpm.TagIssuerApplicationData, t.DataIssuerApplicationData},
+		} {
+			if v.value == "" {
+				continue
+			}
+			b, err := decodeHex(cpm.TagName(v.tag), v.value)
+			if err != nil {
+				return nil, err
+			}
+			c.values[v.tag] = b
+		}
+	}
+	if c.id.PAN == "" {
+		return nil, errors.New("crypto: Application PAN (5A) or Track 2 Equivalent Data (57) is mandatory")
+	}
+	if _, ok := c.values[cpm.TagApplicationTransactionCounter]; !ok {
+		return nil, errors.New("crypto: Application Transaction Counter (9F36) is mandatory")
+	}
+	return c, nil
+}
+
+// Cryptogram computes the Application Cryptogram of tx over the DefaultCDOL
+// followed by the Issuer Application Data. The card data is read from
+// templates, outermost first, so that a transparent template can take the PAN
+// from its Common Data Template.
+func Cryptogram(keys KeyProvider, tx Transaction, templates ...*cpm.BERTLV) ([]byte, error) {
+	card, err := newCardData(templates)
+	if err != nil {
+		return nil, err
+	}
+	return card.cryptogram(keys, tx)
+}
+
+func (c *cardData) atc() []byte {
+	return c.values[cpm.TagApplicationTransactionCounter]
+}
+
+func (c *cardData) cryptogram(keys KeyProvider, tx Transaction) ([]byte, error) {
+	mk, err := keys.MasterKey(c.id)
+	if err != nil {
+		return nil, err
+	}
+	values, err := tx.values()
+	if err != nil {
+		return nil, err
+	}
+	for tag, v := range c.values {
+		values[tag] = v
+	}
+	data := append(DefaultCDOL.Build(values), c.values[cpm.TagIssuerApplicationData]...)
+	return ApplicationCryptogram(mk, c.atc(), data)
+}
+
+// TransparentTemplate is a template holding an Application Cryptogram, with
+// the templates it takes card data from, outermost first.
+type TransparentTemplate struct {
+	Path    string
+	BERTLV  *cpm.BERTLV
+	Parents []*cpm.BERTLV
+}
+
+// Templates returns the parents of t followed by t.
+func (t TransparentTemplate) Templates() []*cpm.BERTLV {
+	return append(append([]*cpm.BERTLV{}, t.Parents...), t.BERTLV)
+}
+
+// TransparentTemplates returns the transparent templates of qr. Application
+// Specific Transparent Templates take the card data they lack from their
+// Application Template and the first Common Data Template.
+func TransparentTemplates(qr *cpm.EMVQR) []TransparentTemplate {
+	var common *cpm.BERTLV
+	if len(qr.CommonDataTemplates) > 0 {
+		common = &qr.CommonDataTemplates[0].BERTLV
+	}
+	var tts []TransparentTemplate
+	for i := range qr.ApplicationTemplates {
+		at := &qr.ApplicationTemplates[i]
+		for j := range at.ApplicationSpecificTransparentTemplates {
+			parents := []*cpm.BERTLV{&at.BERTLV}
+			if common != nil {
+				parents = []*cpm.BERTLV{common, &at.BERTLV}
+			}
+			tts = append(tts, TransparentTemplate{
+				Path:    fmt.Sprintf("%s[%d] > %s[%d]", cpm.IDApplicationTemplate, i, cpm.IDApplicationSpecificTransparentTemplate, j),
+				BERTLV:  &at.ApplicationSpecificTransparentTemplates[j].BERTLV,
+				Parents: parents,
+			})
+		}
+	}
+	for i := range qr.CommonDataTemplates {
+		cdt := &qr.CommonDataTemplates[i]
+		for j := range cdt.CommonDataTransparentTemplates {
+			tts = append(tts, TransparentTemplate{
+				Path:    fmt.Sprintf("%s[%d] > %s[%d]", cpm.IDCommonDataTemplate, i, cpm.IDCommonDataTransparentTemplate, j),
+				BERTLV:  &cdt.CommonDataTransparentTemplates[j].BERTLV,
+				Parents: []*cpm.BERTLV{&cdt.BERTLV},
+			})
+		}
+	}
+	return tts
+}
+
+// errNoTransparentTemplate is returned for payloads with no cryptogram to sign or verify.
+var errNoTransparentTemplate = errors.New("crypto: payload has no transparent template")
+
+// Sign computes the Application Cryptogram of every transparent template of qr
+// and stores it in 9F26.
+func Sign(keys KeyProvider, tx Transaction, qr *cpm.EMVQR) error {
+	tts := TransparentTemplates(qr)
+	if len(tts) == 0 {
+		return errNoTransparentTemplate
+	}
+	for _, tt := range tts {
+		ac, err := Cryptogram(keys, tx, tt.Templates()...)
+		if err != nil {
+			return fmt.Errorf("%s: %w", tt.Path, err)
+		}
+		tt.BERTLV.DataApplicationCryptogram = encodeHex(ac)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package crypto
+
+import (
+	"sync"
+)
+
+// SoftwareKeyStore keeps master keys in memory, for testing and for wallets
+// without a secure element.
+type SoftwareKeyStore struct {
+	mu   sync.RWMutex
+	keys map[KeyID]MasterKey
+}
+
+// NewSoftwareKeyStore ...
+func NewSoftwareKeyStore() *SoftwareKeyStore {
+	return &SoftwareKeyStore{
+		keys: make(map[KeyID]MasterKey),
+	}
+}
+
+// Add ...
+func (s *SoftwareKeyStore) Add(id KeyID, key MasterKey) error {
+	if err := key.Validate(); err != nil {
+		return err
+	}
+	key.Key = append([]byte{}, key.Key...)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[id] = key
+	return nil
+}
+
+// MasterKey ...
+func (s *SoftwareKeyStore) MasterKey(id KeyID) (MasterKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, ok := s.keys[id]
+	if !ok {
+		return MasterKey{}, ErrKeyNotFound
+	}
+	key.Key = append([]byte{}, key.Key...)
+	return key, nil
+}
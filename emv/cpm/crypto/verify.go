@@ -0,0 +1,164 @@
+package crypto
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/dongri/emv-qrcode/emv/cpm"
+)
+
+// Status is the outcome of a cryptogram verification.
+type Status int
+
+// const ...
+const (
+	StatusValid      Status = iota // the cryptogram matches and the ATC is new
+	StatusInvalid                  // the cryptogram does not match
+	StatusATCReplay                // the cryptogram matches but the ATC was accepted before
+	StatusKeyMissing               // the KeyProvider has no master key for the card
+	StatusMalformed                // the payload lacks data the cryptogram needs
+)
+
+// String ...
+func (s Status) String() string {
+	switch s {
+	case StatusValid:
+		return "valid"
+	case StatusInvalid:
+		return "invalid"
+	case StatusATCReplay:
+		return "ATC replay"
+	case StatusKeyMissing:
+		return "key missing"
+	case StatusMalformed:
+		return "malformed"
+	}
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+// Result is the verification of a transparent template. Path locates the
+// template in the payload, as "62[0] > 64[0]".
+type Result struct {
+	Status Status
+	Path   string
+	KeyID  KeyID
+	ATC    int
+	Err    error // the cause of StatusKeyMissing and StatusMalformed
+}
+
+// Valid ...
+func (r Result) Valid() bool {
+	return r.Status == StatusValid
+}
+
+// ATCTracker remembers the ATCs accepted per card, to detect replayed payloads.
+type ATCTracker interface {
+	// Accept records atc for the card if it is above the highest ATC accepted
+	// so far, and reports whether it was.
+	Accept(id KeyID, atc int) (bool, error)
+}
+
+// MemoryATCTracker ...
+type MemoryATCTracker struct {
+	mu   sync.Mutex
+	atcs map[KeyID]int
+}
+
+// NewMemoryATCTracker ...
+func NewMemoryATCTracker() *MemoryATCTracker {
+	return &MemoryATCTracker{
+		atcs: make(map[KeyID]int),
+	}
+}
+
+// Accept ...
+func (m *MemoryATCTracker) Accept(id KeyID, atc int) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if last, ok := m.atcs[id]; ok && atc <= last {
+		return false, nil
+	}
+	m.atcs[id] = atc
+	return true, nil
+}
+
+// Verifier checks the Application Cryptograms of decoded payloads, as an
+// issuer would.
+type Verifier struct {
+	Keys KeyProvider
+	ATCs ATCTracker // replays are not detected when nil
+}
+
+// Verify checks the Application Cryptogram (9F26) of every transparent
+// template of qr for tx. The ATCs of valid cryptograms are accepted by the
+// ATCTracker once all of them are checked, so a payload with an invalid
+// cryptogram does not use up its ATC. The error is only for payloads with no
+// transparent template and ATCTracker failures.
+func (v *Verifier) Verify(tx Transaction, qr *cpm.EMVQR) ([]Result, error) {
+	tts := TransparentTemplates(qr)
+	if len(tts) == 0 {
+		return nil, errNoTransparentTemplate
+	}
+	results := make([]Result, len(tts))
+	for i, tt := range tts {
+		results[i] = v.verify(tx, tt)
+	}
+	if v.ATCs == nil {
+		return results, nil
+	}
+	for i := range results {
+		if !results[i].Valid() {
+			continue
+		}
+		ok, err := v.ATCs.Accept(results[i].KeyID, results[i].ATC)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			results[i].Status = StatusATCReplay
+		}
+	}
+	return results, nil
+}
+
+func (v *Verifier) verify(tx Transaction, tt TransparentTemplate) Result {
+	r := Result{Path: tt.Path}
+	malformed := func(err error) Result {
+		r.Status = StatusMalformed
+		r.Err = err
+		return r
+	}
+	card, err := newCardData(tt.Templates())
+	if err != nil {
+		return malformed(err)
+	}
+	r.KeyID = card.id
+	if len(card.atc()) != 2 {
+		return malformed(fmt.Errorf("crypto: ATC should be 2 bytes, length: %d", len(card.atc())))
+	}
+	r.ATC = int(card.atc()[0])<<8 | int(card.atc()[1])
+	if tt.BERTLV.DataApplicationCryptogram == "" {
+		return malformed(errors.New("crypto: Application Cryptogram (9F26) is mandatory"))
+	}
+	got, err := decodeHex(cpm.TagName(cpm.TagApplicationCryptogram), tt.BERTLV.DataApplicationCryptogram)
+	if err != nil {
+		return malformed(err)
+	}
+	want, err := card.cryptogram(v.Keys, tx)
+	switch {
+	case errors.Is(err, ErrKeyNotFound):
+		r.Status = StatusKeyMissing
+		r.Err = err
+		return r
+	case err != nil:
+		return malformed(err)
+	}
+	if subtle.ConstantTimeCompare(got, want) != 1 {
+		r.Status = StatusInvalid
+		return r
+	}
+	r.Status = StatusValid
+	return r
+}
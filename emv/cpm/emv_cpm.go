@@ -4,7 +4,6 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
-	"strconv"
 	"unicode/utf8"
 )
 
@@ -82,6 +81,107 @@ type BERTLV struct {
 	DataApplicationCryptogram         string // "9F26"
 	DataApplicationTransactionCounter string // "9F36"
 	DataUnpredictableNumber           string // "9F37"
+	DataApplicationInterchangeProfile string // "82"
+	DataApplicationExpirationDate     string // "5F24"
+	DataPANSequenceNumber             string // "5F34"
+	DataApplicationUsageControl       string // "9F07"
+	DataCryptogramInformationData     string // "9F27"
+	DataCVMResults                    string // "9F34"
+	DataThirdPartyData                string // "9F6E"
+	// Additional holds the data objects without a field above, such as issuer
+	// proprietary tags, in payload order.
+	Additional []TLV
+}
+
+// field is a data object of BERTLV. Values of text formats are held as is,
+// the others in hex.
+type field struct {
+	tag   string
+	value *string
+}
+
+func (t *BERTLV) fields() []field {
+	return []field{
+		{TagApplicationDefinitionFileName, &t.DataApplicationDefinitionFileName},
+		{TagApplicationLabel, &t.DataApplicationLabel},
+		{TagTrack2EquivalentData, &t.DataTrack2EquivalentData},
+		{TagApplicationPAN, &t.DataApplicationPAN},
+		{TagCardholderName, &t.DataCardholderName},
+		{TagLanguagePreference, &t.DataLanguagePreference},
+		{TagIssuerURL, &t.DataIssuerURL},
+		{TagApplicationVersionNumber, &t.DataApplicationVersionNumber},
+		{TagIssuerApplicationData, &t.DataIssuerApplicationData},
+		{TagTokenRequestorID, &t.DataTokenRequestorID},
+		{TagPaymentAccountReference, &t.DataPaymentAccountReference},
+		{TagLast4DigitsOfPAN, &t.DataLast4DigitsOfPAN},
+		{TagApplicationCryptogram, &t.DataApplicationCryptogram},
+		{TagApplicationTransactionCounter, &t.DataApplicationTransactionCounter},
+		{TagUnpredictableNumber, &t.DataUnpredictableNumber},
+		{TagApplicationInterchangeProfile, &t.DataApplicationInterchangeProfile},
+		{TagApplicationExpirationDate, &t.DataApplicationExpirationDate},
+		{TagPANSequenceNumber, &t.DataPANSequenceNumber},
+		{TagApplicationUsageControl, &t.DataApplicationUsageControl},
+		{TagCryptogramInformationData, &t.DataCryptogramInformationData},
+		{TagCVMResults, &t.DataCVMResults},
+		{TagThirdPartyData, &t.DataThirdPartyData},
+	}
+}
+
+// hexFields are the text tags whose fields hold hex nonetheless:
+// DataPaymentAccountReference has been hex since before the tag dictionary.
+var hexFields = map[string]bool{
+	TagPaymentAccountReference: true,
+}
+
+func (f field) text() bool {
+	if hexFields[f.tag] {
+		return false
+	}
+	info, _ := LookupTag(f.tag)
+	return info.Format.Text()
+}
+
+// bytes returns the value of f as it is encoded in the payload.
+func (f field) bytes() ([]byte, error) {
+	if f.text() {
+		return []byte(*f.value), nil
+	}
+	b, err := hex.DecodeString(*f.value)
+	if err != nil {
+		return nil, fmt.Errorf("value of %s should be even-length hex, value: %s", f.tag, *f.value)
+	}
+	return b, nil
+}
+
+// Validate checks the data objects against the tag dictionary, and that the
+// PAN of the Track 2 Equivalent Data matches the Application PAN.
+func (t *BERTLV) Validate() error {
+	for _, f := range t.fields() {
+		if *f.value == "" {
+			continue
+		}
+		b, err := f.bytes()
+		if err != nil {
+			return err
+		}
+		if err := ValidateTag(f.tag, b); err != nil {
+			return err
+		}
+	}
+	for _, tlv := range t.Additional {
+		if err := ValidateTag(tlv.Tag, tlv.Value); err != nil {
+			return err
+		}
+	}
+	return t.validateTrack2()
+}
+
+// Encode ...
+func Encode(qr *EMVQR) (string, error) {
+	if err := qr.Validate(); err != nil {
+		return "", err
+	}
+	return qr.GeneratePayload()
 }
 
 // GeneratePayload ...
@@ -93,11 +193,18 @@ func (c *EMVQR) GeneratePayload() (string, error) {
 		return "", fmt.Errorf("DataPayloadFormatIndicator is mandatory")
 	}
 	if len(c.ApplicationTemplates) > 0 {
-		for _, t := range c.ApplicationTemplates {
-			template := formattingTemplate((t.BERTLV))
+		for i, t := range c.ApplicationTemplates {
+			path := fmt.Sprintf("%s[%d]", IDApplicationTemplate, i)
+			template, err := formattingTemplate(t.BERTLV)
+			if err != nil {
+				return "", fmt.Errorf("%s: %s", path, err)
+			}
 			if len(t.ApplicationSpecificTransparentTemplates) > 0 {
-				for _, tt := range t.ApplicationSpecificTransparentTemplates {
-					ttemplate := formattingTemplate((tt.BERTLV))
+				for j, tt := range t.ApplicationSpecificTransparentTemplates {
+					ttemplate, err := formattingTemplate(tt.BERTLV)
+					if err != nil {
+						return "", fmt.Errorf("%s > %s[%d]: %s", path, IDApplicationSpecificTransparentTemplate, j, err)
+					}
 					template += format(IDApplicationSpecificTransparentTemplate, ttemplate)
 				}
 			}
@@ -105,11 +212,18 @@ func (c *EMVQR) GeneratePayload() (string, error) {
 		}
 	}
 	if len(c.CommonDataTemplates) > 0 {
-		for _, t := range c.CommonDataTemplates {
-			template := formattingTemplate(t.BERTLV)
+		for i, t := range c.CommonDataTemplates {
+			path := fmt.Sprintf("%s[%d]", IDCommonDataTemplate, i)
+			template, err := formattingTemplate(t.BERTLV)
+			if err != nil {
+				return "", fmt.Errorf("%s: %s", path, err)
+			}
 			if len(t.CommonDataTransparentTemplates) > 0 {
-				for _, tt := range t.CommonDataTransparentTemplates {
-					ttemplate := formattingTemplate(tt.BERTLV)
+				for j, tt := range t.CommonDataTransparentTemplates {
+					ttemplate, err := formattingTemplate(tt.BERTLV)
+					if err != nil {
+						return "", fmt.Errorf("%s > %s[%d]: %s", path, IDCommonDataTransparentTemplate, j, err)
+					}
 					template += format(IDCommonDataTransparentTemplate, ttemplate)
 				}
 			}
@@ -126,9 +240,13 @@ func (c *EMVQR) GeneratePayload() (string, error) {
 
 func format(id, value string) string {
 	length := utf8.RuneCountInString(value) / 2
-	lengthStr := strconv.Itoa(length)
-	lengthStr = "00" + fmt.Sprintf("%X", length)
-	return id + lengthStr[len(lengthStr)-2:] + value
+	switch {
+	case length > 0xFF:
+		return id + fmt.Sprintf("82%04X", length) + value
+	case length > 0x7F:
+		return id + fmt.Sprintf("81%02X", length) + value
+	}
+	return id + fmt.Sprintf("%02X", length) + value
 }
 
 func toHex(s string) string {
@@ -138,52 +256,20 @@ func toHex(s string) string {
 	return string(dst)
 }
 
-func formattingTemplate(t BERTLV) string {
+func formattingTemplate(t BERTLV) (string, error) {
 	template := ""
-	if t.DataApplicationDefinitionFileName != "" {
-		template += format(TagApplicationDefinitionFileName, t.DataApplicationDefinitionFileName)
-	}
-	if t.DataApplicationLabel != "" {
-		template += format(TagApplicationLabel, toHex(t.DataApplicationLabel))
-	}
-	if t.DataTrack2EquivalentData != "" {
-		template += format(TagTrack2EquivalentData, t.DataTrack2EquivalentData)
-	}
-	if t.DataApplicationPAN != "" {
-		template += format(TagApplicationPAN, t.DataApplicationPAN)
-	}
-	if t.DataCardholderName != "" {
-		template += format(TagCardholderName, toHex(t.DataCardholderName))
-	}
-	if t.DataLanguagePreference != "" {
-		template += format(TagLanguagePreference, toHex(t.DataLanguagePreference))
-	}
-	if t.DataIssuerURL != "" {
-		template += format(TagIssuerURL, toHex(t.DataIssuerURL))
-	}
-	if t.DataApplicationVersionNumber != "" {
-		template += format(TagApplicationVersionNumber, t.DataApplicationVersionNumber)
-	}
-	if t.DataIssuerApplicationData != "" {
-		template += format(TagIssuerApplicationData, t.DataIssuerApplicationData)
-	}
-	if t.DataTokenRequestorID != "" {
-		template += format(TagTokenRequestorID, t.DataTokenRequestorID)
-	}
-	if t.DataPaymentAccountReference != "" {
-		template += format(TagPaymentAccountReference, t.DataPaymentAccountReference)
-	}
-	if t.DataLast4DigitsOfPAN != "" {
-		template += format(TagLast4DigitsOfPAN, t.DataLast4DigitsOfPAN)
-	}
-	if t.DataApplicationCryptogram != "" {
-		template += format(TagApplicationCryptogram, t.DataApplicationCryptogram)
-	}
-	if t.DataApplicationTransactionCounter != "" {
-		template += format(TagApplicationTransactionCounter, t.DataApplicationTransactionCounter)
+	for _, f := range t.fields() {
+		if *f.value == "" {
+			continue
+		}
+		b, err := f.bytes()
+		if err != nil {
+			return "", err
+		}
+		template += format(f.tag, hex.EncodeToString(b))
 	}
-	if t.DataUnpredictableNumber != "" {
-		template += format(TagUnpredictableNumber, t.DataUnpredictableNumber)
+	for _, tlv := range t.Additional {
+		template += format(tlv.Tag, hex.EncodeToString(tlv.Value))
 	}
-	return template
+	return template, nil
 }
@@ -0,0 +1,174 @@
+package cpm
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const samplePayload = "hQVDUFYwMWETTwegAAAAVVVVUAhQcm9kdWN0MWETTwegAAAAZmZmUAhQcm9kdWN0MmJJWggSNFZ4kBI0WF8gDkNBUkRIT0xERVIvRU1WXy0IcnVlc2RlZW5kIZ8QBwYBCgMAAACfJghYT9OF+iNLzJ82AgABnzcEbVjvEw=="
+
+func sampleEMVQR() *EMVQR {
+	qr := &EMVQR{DataPayloadFormatIndicator: "CPV01"}
+	qr.ApplicationTemplates = []ApplicationTemplate{
+		{BERTLV: BERTLV{DataApplicationDefinitionFileName: "A0000000555555", DataApplicationLabel: "Product1"}},
+		{BERTLV: BERTLV{DataApplicationDefinitionFileName: "A0000000666666", DataApplicationLabel: "Product2"}},
+	}
+	cdt := CommonDataTemplate{BERTLV: BERTLV{
+		DataApplicationPAN:     "1234567890123458",
+		DataCardholderName:     "CARDHOLDER/EMV",
+		DataLanguagePreference: "ruesdeen",
+	}}
+	cdt.CommonDataTransparentTemplates = []CommonDataTransparentTemplate{
+		{BERTLV: BERTLV{
+			DataIssuerApplicationData:         "06010A03000000",
+			DataApplicationCryptogram:         "584FD385FA234BCC",
+			DataApplicationTransactionCounter: "0001",
+			DataUnpredictableNumber:           "6D58EF13",
+		}},
+	}
+	qr.CommonDataTemplates = []CommonDataTemplate{cdt}
+	return qr
+}
+
+func TestParseEMVQR(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    *EMVQR
+		wantErr bool
+	}{
+		{
+			name:    "ok",
+			payload: samplePayload,
+			want:    sampleEMVQR(),
+		},
+		{
+			name:    "empty",
+			payload: "",
+			want:    &EMVQR{},
+		},
+		{
+			name:    "not base64",
+			payload: "!!",
+			wantErr: true,
+		},
+		{
+			name:    "truncated",
+			payload: samplePayload[:20],
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEMVQR(tt.payload)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseEMVQR() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseEMVQR() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTLV(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		want    []TLV
+		wantErr bool
+	}{
+		{
+			name: "short and multi byte tags",
+			data: []byte{0x85, 0x01, 0x41, 0x9F, 0x36, 0x02, 0x00, 0x01},
+			want: []TLV{{Tag: "85", Value: []byte{0x41}}, {Tag: "9F36", Value: []byte{0x00, 0x01}}},
+		},
+		{
+			name: "long form length",
+			data: append([]byte{0x50, 0x81, 0x80}, make([]byte, 0x80)...),
+			want: []TLV{{Tag: "50", Value: make([]byte, 0x80)}},
+		},
+		{
+			name:    "truncated tag",
+			data:    []byte{0x9F},
+			wantErr: true,
+		},
+		{
+			name:    "missing length",
+			data:    []byte{0x85},
+			wantErr: true,
+		},
+		{
+			name:    "truncated length",
+			data:    []byte{0x85, 0x82, 0x01},
+			wantErr: true,
+		},
+		{
+			name:    "indefinite length",
+			data:    []byte{0x85, 0x80},
+			wantErr: true,
+		},
+		{
+			name:    "value out of range",
+			data:    []byte{0x85, 0x05, 0x41},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTLV(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseTLV() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTLV() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEMVQR_GeneratePayload_longValue(t *testing.T) {
+	qr := sampleEMVQR()
+	qr.CommonDataTemplates[0].DataIssuerURL = "https://example.com/" + strings.Repeat("a", 300)
+	payload, err := qr.GeneratePayload()
+	if err != nil {
+		t.Fatalf("EMVQR.GeneratePayload() error = %v", err)
+	}
+	got, err := ParseEMVQR(payload)
+	if err != nil {
+		t.Fatalf("ParseEMVQR() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, qr) {
+		t.Errorf("ParseEMVQR() = %v, want %v", got, qr)
+	}
+}
+
+// FuzzParseEMVQR checks that decoding never panics and that whatever decodes
+// survives a GeneratePayload round trip.
+func FuzzParseEMVQR(f *testing.F) {
+	f.Add(samplePayload)
+	f.Add("")
+	f.Add(base64.StdEncoding.EncodeToString([]byte{0x61, 0x81, 0x02, 0x4F, 0x00}))
+	f.Add(base64.StdEncoding.EncodeToString([]byte{0x9F, 0xFF, 0xFF}))
+	f.Fuzz(func(t *testing.T, payload string) {
+		qr, err := ParseEMVQR(payload)
+		if err != nil || qr.DataPayloadFormatIndicator == "" {
+			return
+		}
+		generated, err := qr.GeneratePayload()
+		if err != nil {
+			t.Fatalf("EMVQR.GeneratePayload() error = %v", err)
+		}
+		got, err := ParseEMVQR(generated)
+		if err != nil {
+			t.Fatalf("ParseEMVQR(%q) error = %v", generated, err)
+		}
+		if !reflect.DeepEqual(got, qr) {
+			t.Fatalf("ParseEMVQR(%q) = %v, want %v", generated, got, qr)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package cpm
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dongri/emv-qrcode/redact"
+)
+
+// DumpOptions ...
+type DumpOptions struct {
+	Color  bool             // ANSI colours, for terminals
+	Redact *redact.Redactor // redacts the PAN, Track 2, cardholder name and expiry date, redact.Default if nil
+}
+
+// redactor returns the redactor of o, &redact.Redactor{} printing values as is.
+func (o DumpOptions) redactor() redact.Redactor {
+	if o.Redact == nil {
+		return redact.Default
+	}
+	return *o.Redact
+}
+
+// ANSI colours used by Dump.
+const (
+	colorReset  = "\x1b[0m"
+	colorOffset = "\x1b[2m"  // dim
+	colorTag    = "\x1b[36m" // cyan
+	colorValue  = "\x1b[32m" // green
+)
+
+// Dump writes the BER-TLV data objects of payload to w as a tree, with the
+// offset of each data object in the decoded bytes and its value in hex:
+//
+//	0000 85 05 Payload Format Indicator: 43 50 56 30 31 |CPV01|
+//	0007 61 13 Application Template
+//	0009   4F 07 Application Definition File (ADF) Name: A0 00 00 00 55 55 55
+//
+// If the payload is broken, the data objects before the error are written and
+// the error is returned.
+func Dump(w io.Writer, payload string, opts DumpOptions) error {
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return err
+	}
+	d := &dumper{w: w, opts: opts, root: data}
+	if err := d.dump(data, 0, 0); err != nil {
+		return err
+	}
+	return d.err
+}
+
+type dumper struct {
+	w    io.Writer
+	opts DumpOptions
+	root []byte
+	err  error
+}
+
+func (d *dumper) dump(data []byte, offset, depth int) error {
+	tlvs, err := ParseTLV(data)
+	for _, tlv := range tlvs {
+		// values are sub slices of root, so their capacity tells where they start
+		end := cap(d.root) - cap(tlv.Value) + len(tlv.Value)
+		var sb strings.Builder
+		sb.WriteString(d.paint(colorOffset, fmt.Sprintf("%04X", offset)) + " ")
+		sb.WriteString(strings.Repeat("  ", depth))
+		sb.WriteString(d.paint(colorTag, fmt.Sprintf("%s %02X", tlv.Tag, len(tlv.Value))))
+		if name := TagName(tlv.Tag); name != "" {
+			sb.WriteString(" " + name)
+		}
+		if isConstructed(tlv.Tag) {
+			d.println(sb.String())
+			if err := d.dump(tlv.Value, end-len(tlv.Value), depth+1); err != nil {
+				return err
+			}
+		} else {
+			info, _ := LookupTag(tlv.Tag)
+			switch {
+			case d.opts.redactor().Policy != redact.None && sensitiveTags[tlv.Tag]:
+				value := fmt.Sprintf("%X", tlv.Value)
+				if info.Format.Text() {
+					value = string(tlv.Value)
+				}
+				sb.WriteString(": " + d.paint(colorValue, redactValue(d.opts.redactor(), tlv.Tag, value)))
+			case info.Format.Text():
+				sb.WriteString(": " + d.paint(colorValue, hexBytes(tlv.Value)) + " |" + string(tlv.Value) + "|")
+			default:
+				sb.WriteString(": " + d.paint(colorValue, hexBytes(tlv.Value)))
+			}
+			d.println(sb.String())
+		}
+		offset = end
+	}
+	return err
+}
+
+func hexBytes(b []byte) string {
+	s := make([]string, len(b))
+	for i, c := range b {
+		s[i] = fmt.Sprintf("%02X", c)
+	}
+	return strings.Join(s, " ")
+}
+
+func (d *dumper) paint(color, s string) string {
+	if !d.opts.Color {
+		return s
+	}
+	return color + s + colorReset
+}
+
+func (d *dumper) println(s string) {
+	if d.err != nil {
+		return
+	}
+	_, d.err = fmt.Fprintln(d.w, s)
+}
@@ -0,0 +1,70 @@
+package cpm
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dongri/emv-qrcode/redact"
+)
+
+func TestDump(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		opts    DumpOptions
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "ok",
+			payload: "hQVDUFYwMWETTwegAAAAVVVVUAhQcm9kdWN0MQ==",
+			want: "0000 85 05 Payload Format Indicator: 43 50 56 30 31 |CPV01|\n" +
+				"0007 61 13 Application Template\n" +
+				"0009   4F 07 Application Definition File (ADF) Name: A0 00 00 00 55 55 55\n" +
+				"0012   50 08 Application Label: 50 72 6F 64 75 63 74 31 |Product1|\n",
+		},
+		{
+			name:    "redact by default",
+			payload: "YhtaCBI0VniQEjRYXyAOQ0FSREhPTERFUi9FTVY=",
+			want: "0000 62 1B Common Data Template\n" +
+				"0002   5A 08 Application PAN: 123456******3458\n" +
+				"000C   5F20 0E Cardholder Name: **************\n",
+		},
+		{
+			name:    "without redaction",
+			payload: "YhtaCBI0VniQEjRYXyAOQ0FSREhPTERFUi9FTVY=",
+			opts:    DumpOptions{Redact: &redact.Redactor{}},
+			want: "0000 62 1B Common Data Template\n" +
+				"0002   5A 08 Application PAN: 12 34 56 78 90 12 34 58\n" +
+				"000C   5F20 0E Cardholder Name: 43 41 52 44 48 4F 4C 44 45 52 2F 45 4D 56 |CARDHOLDER/EMV|\n",
+		},
+		{
+			name:    "color",
+			payload: "nzYCAAE=",
+			opts:    DumpOptions{Color: true},
+			want:    "\x1b[2m0000\x1b[0m \x1b[36m9F36 02\x1b[0m Application Transaction Counter: \x1b[32m00 01\x1b[0m\n",
+		},
+		{
+			name:    "broken",
+			payload: "hQVDUFYwMWEU",
+			want:    "0000 85 05 Payload Format Indicator: 43 50 56 30 31 |CPV01|\n",
+			wantErr: true,
+		},
+		{
+			name:    "not base64",
+			payload: "!!",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Dump(&buf, tt.payload, tt.opts); (err != nil) != tt.wantErr {
+				t.Errorf("Dump() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Dump() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
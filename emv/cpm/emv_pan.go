@@ -0,0 +1,45 @@
+package cpm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dongri/emv-qrcode/emv/mpm"
+	"github.com/dongri/emv-qrcode/pan"
+)
+
+// networks returns the payment networks of the AIDs (4F) of the templates,
+// leaving out the AIDs of unknown networks.
+func networks(templates ...ApplicationTemplate) []string {
+	var names []string
+	for _, t := range templates {
+		if name := mpm.NetworkName(t.DataApplicationDefinitionFileName); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// validateBrand checks the Application PAN (5A) of t: a PAN of a known brand
+// has to pass pan.Validate and belong to one of networks, the networks of the
+// applications t applies to. No networks, as for unknown AIDs, skips the
+// brand check.
+func validateBrand(path string, t BERTLV, networks []string) error {
+	p := strings.TrimRight(strings.ToUpper(t.DataApplicationPAN), "F")
+	brand := pan.BrandOf(p)
+	if brand == "" {
+		return nil
+	}
+	if err := pan.Validate(p); err != nil {
+		return fmt.Errorf("%s: DataApplicationPAN (%s): %s", path, TagApplicationPAN, err)
+	}
+	if len(networks) == 0 {
+		return nil
+	}
+	for _, network := range networks {
+		if network == brand.Network() {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: DataApplicationPAN (%s) brand should be %s, brand: %s", path, TagApplicationPAN, strings.Join(networks, " or "), brand)
+}
@@ -0,0 +1,165 @@
+package cpm
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// TLV ...
+// Tag is the tag in upper case hex, Value the raw value bytes.
+type TLV struct {
+	Tag   string
+	Value []byte
+}
+
+// Decode ...
+func Decode(payload string) (*EMVQR, error) {
+	qr, err := ParseEMVQR(payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := qr.Validate(); err != nil {
+		return qr, err
+	}
+	return qr, nil
+}
+
+// ParseEMVQR ...
+// payload is the base64 encoded BER-TLV data generated by GeneratePayload.
+// Unknown data objects are ignored, transparent templates outside of their
+// template are an error.
+func ParseEMVQR(payload string) (*EMVQR, error) {
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, err
+	}
+	tlvs, err := ParseTLV(data)
+	if err != nil {
+		return nil, err
+	}
+	qr := &EMVQR{}
+	for _, tlv := range tlvs {
+		switch tlv.Tag {
+		case IDPayloadFormatIndicator:
+			qr.DataPayloadFormatIndicator = string(tlv.Value)
+		case IDApplicationTemplate:
+			t := ApplicationTemplate{}
+			children, err := ParseTLV(tlv.Value)
+			if err != nil {
+				return nil, err
+			}
+			for _, child := range children {
+				if child.Tag != IDApplicationSpecificTransparentTemplate {
+					t.set(child)
+					continue
+				}
+				tt := ApplicationSpecificTransparentTemplate{}
+				if err := tt.parse(child.Value); err != nil {
+					return nil, err
+				}
+				t.ApplicationSpecificTransparentTemplates = append(t.ApplicationSpecificTransparentTemplates, tt)
+			}
+			qr.ApplicationTemplates = append(qr.ApplicationTemplates, t)
+		case IDCommonDataTemplate:
+			t := CommonDataTemplate{}
+			children, err := ParseTLV(tlv.Value)
+			if err != nil {
+				return nil, err
+			}
+			for _, child := range children {
+				if child.Tag != IDCommonDataTransparentTemplate {
+					t.set(child)
+					continue
+				}
+				tt := CommonDataTransparentTemplate{}
+				if err := tt.parse(child.Value); err != nil {
+					return nil, err
+				}
+				t.CommonDataTransparentTemplates = append(t.CommonDataTransparentTemplates, tt)
+			}
+			qr.CommonDataTemplates = append(qr.CommonDataTemplates, t)
+		case IDApplicationSpecificTransparentTemplate:
+			return nil, fmt.Errorf("%s should be inside %s", tlv.Tag, IDApplicationTemplate)
+		case IDCommonDataTransparentTemplate:
+			return nil, fmt.Errorf("%s should be inside %s", tlv.Tag, IDCommonDataTemplate)
+		}
+	}
+	return qr, nil
+}
+
+// ParseTLV parses one level of BER-TLV data objects.
+// On error the data objects before it are returned with the error.
+func ParseTLV(data []byte) ([]TLV, error) {
+	var tlvs []TLV
+	for pos := 0; pos < len(data); {
+		start := pos
+		// tag
+		if data[pos]&0x1F == 0x1F {
+			pos++
+			for pos < len(data) && data[pos]&0x80 == 0x80 {
+				pos++
+			}
+		}
+		pos++
+		if pos > len(data) {
+			return tlvs, fmt.Errorf("tag is truncated, offset: %d", start)
+		}
+		tag := strings.ToUpper(hex.EncodeToString(data[start:pos]))
+		// length
+		if pos >= len(data) {
+			return tlvs, fmt.Errorf("length of %s is missing, offset: %d", tag, start)
+		}
+		length := int(data[pos])
+		pos++
+		if length&0x80 == 0x80 {
+			n := length & 0x7F
+			if n == 0 || n > 2 {
+				return tlvs, fmt.Errorf("length of %s should be 1 to 3 bytes, offset: %d", tag, start)
+			}
+			if pos+n > len(data) {
+				return tlvs, fmt.Errorf("length of %s is truncated, offset: %d", tag, start)
+			}
+			length = 0
+			for _, b := range data[pos : pos+n] {
+				length = length<<8 | int(b)
+			}
+			pos += n
+		}
+		// value
+		if length > len(data)-pos {
+			return tlvs, fmt.Errorf("value of %s is out of range, offset: %d, length: %d, available: %d", tag, start, length, len(data)-pos)
+		}
+		tlvs = append(tlvs, TLV{Tag: tag, Value: data[pos : pos+length]})
+		pos += length
+	}
+	return tlvs, nil
+}
+
+func (t *BERTLV) parse(data []byte) error {
+	tlvs, err := ParseTLV(data)
+	if err != nil {
+		return err
+	}
+	for _, tlv := range tlvs {
+		t.set(tlv)
+	}
+	return nil
+}
+
+// set stores a data object in its field, or in Additional if it has none.
+func (t *BERTLV) set(tlv TLV) {
+	for _, f := range t.fields() {
+		if f.tag != tlv.Tag {
+			continue
+		}
+		if f.text() {
+			*f.value = string(tlv.Value)
+		} else {
+			*f.value = strings.ToUpper(hex.EncodeToString(tlv.Value))
+		}
+		return
+	}
+	t.Additional = append(t.Additional, tlv)
+}
@@ -0,0 +1,147 @@
+package cpm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dongri/emv-qrcode/redact"
+)
+
+// FormatRedactor redacts the card data printed through fmt, such as with
+// log.Printf("%v", qr). Set it to redact.Redactor{} to print values as is.
+var FormatRedactor = redact.Default
+
+// sensitiveTags are the tags whose values are redacted.
+var sensitiveTags = map[string]bool{
+	TagTrack2EquivalentData:      true,
+	TagApplicationPAN:            true,
+	TagCardholderName:            true,
+	TagApplicationExpirationDate: true,
+}
+
+// redactValue redacts the value of a data object, in the form it is held in
+// BERTLV, and returns it as is for tags that are not sensitive.
+func redactValue(r redact.Redactor, tag, value string) string {
+	if value == "" || r.Policy == redact.None || !sensitiveTags[tag] {
+		return value
+	}
+	switch tag {
+	case TagApplicationPAN:
+		return r.PAN(strings.TrimRight(strings.ToUpper(value), "F"))
+	case TagTrack2EquivalentData:
+		track2, err := ParseTrack2(value)
+		if err != nil {
+			return r.Value(value)
+		}
+		rest := track2.Expiry + track2.ServiceCode + track2.DiscretionaryData
+		return r.PAN(track2.PAN) + track2Separator + r.Value(rest)
+	}
+	return r.Value(value)
+}
+
+// Redact returns a copy of t with its PAN (5A), Track 2 Equivalent Data (57),
+// Cardholder Name (5F20) and Application Expiration Date (5F24) redacted by r.
+// The copy is for printing only: redacted values are not valid hex.
+func (t *BERTLV) Redact(r redact.Redactor) BERTLV {
+	d := *t
+	for _, f := range d.fields() {
+		*f.value = redactValue(r, f.tag, *f.value)
+	}
+	d.Additional = append([]TLV(nil), t.Additional...)
+	return d
+}
+
+// Redact returns a copy of c with the card data of its templates redacted by r.
+func (c *EMVQR) Redact(r redact.Redactor) *EMVQR {
+	d := &EMVQR{DataPayloadFormatIndicator: c.DataPayloadFormatIndicator}
+	for _, at := range c.ApplicationTemplates {
+		at.BERTLV = at.BERTLV.Redact(r)
+		tts := at.ApplicationSpecificTransparentTemplates
+		at.ApplicationSpecificTransparentTemplates = make([]ApplicationSpecificTransparentTemplate, len(tts))
+		for i := range tts {
+			at.ApplicationSpecificTransparentTemplates[i].BERTLV = tts[i].BERTLV.Redact(r)
+		}
+		d.ApplicationTemplates = append(d.ApplicationTemplates, at)
+	}
+	for _, cdt := range c.CommonDataTemplates {
+		cdt.BERTLV = cdt.BERTLV.Redact(r)
+		tts := cdt.CommonDataTransparentTemplates
+		cdt.CommonDataTransparentTemplates = make([]CommonDataTransparentTemplate, len(tts))
+		for i := range tts {
+			cdt.CommonDataTransparentTemplates[i].BERTLV = tts[i].BERTLV.Redact(r)
+		}
+		d.CommonDataTemplates = append(d.CommonDataTemplates, cdt)
+	}
+	return d
+}
+
+// Format implements fmt.Formatter, printing the templates of c with their
+// card data redacted by FormatRedactor.
+func (c EMVQR) Format(s fmt.State, verb rune) {
+	// the templates format themselves
+	type plain EMVQR
+	fmt.Fprintf(s, directive(s, verb), plain(c))
+}
+
+// Format implements fmt.Formatter, printing t with its card data redacted by FormatRedactor.
+func (t ApplicationTemplate) Format(s fmt.State, verb rune) {
+	fmt.Fprintf(s, directive(s, verb), struct {
+		BERTLV                                  BERTLV
+		ApplicationSpecificTransparentTemplates []ApplicationSpecificTransparentTemplate
+	}{t.BERTLV, t.ApplicationSpecificTransparentTemplates})
+}
+
+// Format implements fmt.Formatter, printing t with its card data redacted by FormatRedactor.
+func (t CommonDataTemplate) Format(s fmt.State, verb rune) {
+	fmt.Fprintf(s, directive(s, verb), struct {
+		BERTLV                         BERTLV
+		CommonDataTransparentTemplates []CommonDataTransparentTemplate
+	}{t.BERTLV, t.CommonDataTransparentTemplates})
+}
+
+// Format implements fmt.Formatter, printing t with its card data redacted by FormatRedactor.
+func (t ApplicationSpecificTransparentTemplate) Format(s fmt.State, verb rune) {
+	fmt.Fprintf(s, directive(s, verb), struct{ BERTLV BERTLV }{t.BERTLV})
+}
+
+// Format implements fmt.Formatter, printing t with its card data redacted by FormatRedactor.
+func (t CommonDataTransparentTemplate) Format(s fmt.State, verb rune) {
+	fmt.Fprintf(s, directive(s, verb), struct{ BERTLV BERTLV }{t.BERTLV})
+}
+
+// Format implements fmt.Formatter, printing t with its card data redacted by FormatRedactor.
+func (t BERTLV) Format(s fmt.State, verb rune) {
+	type plain BERTLV
+	fmt.Fprintf(s, directive(s, verb), plain(t.Redact(FormatRedactor)))
+}
+
+// Format implements fmt.Formatter, printing t with its PAN redacted by
+// FormatRedactor and the other fields masked. String returns t in clear.
+func (t Track2) Format(s fmt.State, verb rune) {
+	fmt.Fprintf(s, directive(s, verb), redactValue(FormatRedactor, TagTrack2EquivalentData, t.String()))
+}
+
+// Format implements fmt.Formatter, printing tk with its PAN redacted by FormatRedactor.
+func (tk Token) Format(s fmt.State, verb rune) {
+	type plain Token
+	tk.PAN = FormatRedactor.PAN(tk.PAN)
+	fmt.Fprintf(s, directive(s, verb), plain(tk))
+}
+
+// directive rebuilds the formatting directive of s and verb, such as "%+v".
+func directive(s fmt.State, verb rune) string {
+	d := "%"
+	for _, flag := range "+-# 0" {
+		if s.Flag(int(flag)) {
+			d += string(flag)
+		}
+	}
+	if width, ok := s.Width(); ok {
+		d += strconv.Itoa(width)
+	}
+	if precision, ok := s.Precision(); ok {
+		d += "." + strconv.Itoa(precision)
+	}
+	return d + string(verb)
+}
@@ -0,0 +1,82 @@
+package cpm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dongri/emv-qrcode/redact"
+)
+
+func TestEMVQR_Redact(t *testing.T) {
+	qr := sampleEMVQR()
+	qr.CommonDataTemplates[0].DataTrack2EquivalentData = "1234567890123458D2512201123F"
+	qr.CommonDataTemplates[0].DataApplicationExpirationDate = "251231"
+	tests := []struct {
+		name     string
+		redactor redact.Redactor
+		want     BERTLV
+	}{
+		{
+			name:     "first 6 last 4",
+			redactor: redact.Default,
+			want: BERTLV{
+				DataApplicationPAN:            "123456******3458",
+				DataTrack2EquivalentData:      "123456******3458D**********",
+				DataCardholderName:            "**************",
+				DataLanguagePreference:        "ruesdeen",
+				DataApplicationExpirationDate: "******",
+			},
+		},
+		{
+			name:     "none",
+			redactor: redact.Redactor{},
+			want:     qr.CommonDataTemplates[0].BERTLV,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := qr.Redact(tt.redactor)
+			if got.CommonDataTemplates[0].BERTLV.DataApplicationPAN != tt.want.DataApplicationPAN ||
+				got.CommonDataTemplates[0].BERTLV.DataTrack2EquivalentData != tt.want.DataTrack2EquivalentData ||
+				got.CommonDataTemplates[0].BERTLV.DataCardholderName != tt.want.DataCardholderName ||
+				got.CommonDataTemplates[0].BERTLV.DataLanguagePreference != tt.want.DataLanguagePreference ||
+				got.CommonDataTemplates[0].BERTLV.DataApplicationExpirationDate != tt.want.DataApplicationExpirationDate {
+				t.Errorf("EMVQR.Redact() = %+v, want %+v", got.CommonDataTemplates[0].BERTLV.Redact(redact.Redactor{}), tt.want)
+			}
+			if n := len(got.CommonDataTemplates[0].CommonDataTransparentTemplates); n != 1 {
+				t.Errorf("EMVQR.Redact() has %d transparent templates, want 1", n)
+			}
+		})
+	}
+	if qr.CommonDataTemplates[0].DataApplicationPAN != "1234567890123458" {
+		t.Errorf("EMVQR.Redact() changed the original")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	qr := sampleEMVQR()
+	qr.CommonDataTemplates[0].DataTrack2EquivalentData = "1234567890123458D2512201123F"
+	track2, _ := qr.CommonDataTemplates[0].Track2()
+	token := Token{PAN: "4895370012345678", TokenRequestorID: "40010030273", PaymentAccountReference: "V0010013018329182347812364851"}
+	for _, verb := range []string{"%v", "%+v", "%#v", "%s"} {
+		for _, v := range []interface{}{qr, *qr, qr.CommonDataTemplates[0], qr.CommonDataTemplates, &qr.CommonDataTemplates[0].BERTLV, *track2, token} {
+			got := fmt.Sprintf(verb, v)
+			if strings.Contains(got, "1234567890123458") || strings.Contains(got, "4895370012345678") || strings.Contains(got, "CARDHOLDER") {
+				t.Errorf("fmt.Sprintf(%q) = %s, has card data in clear", verb, got)
+			}
+		}
+	}
+	got := fmt.Sprintf("%+v", qr.CommonDataTemplates[0])
+	for _, want := range []string{"DataApplicationPAN:123456******3458", "DataLanguagePreference:ruesdeen", "DataUnpredictableNumber:6D58EF13"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("fmt.Sprintf(%%+v) = %s, want %s", got, want)
+		}
+	}
+	if got := fmt.Sprint(*track2); got != "123456******3458D**********" {
+		t.Errorf("fmt.Sprint(Track2) = %s", got)
+	}
+	if got := track2.String(); got != "1234567890123458D2512201123F" {
+		t.Errorf("Track2.String() = %s", got)
+	}
+}
@@ -0,0 +1,166 @@
+package cpm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Format is the EMV format of a data object value.
+type Format string
+
+// const ...
+const (
+	FormatA   Format = "a"   // alphabetic
+	FormatAN  Format = "an"  // alphanumeric
+	FormatANS Format = "ans" // alphanumeric special
+	FormatB   Format = "b"   // binary
+	FormatCN  Format = "cn"  // compressed numeric, left justified and padded with trailing F
+	FormatN   Format = "n"   // numeric, BCD right justified and padded with leading 0
+)
+
+// const ...
+const (
+	TagApplicationInterchangeProfile = "82"
+	TagApplicationExpirationDate     = "5F24"
+	TagPANSequenceNumber             = "5F34"
+	TagApplicationUsageControl       = "9F07"
+	TagCryptogramInformationData     = "9F27"
+	TagCVMResults                    = "9F34"
+	TagThirdPartyData                = "9F6E"
+)
+
+// TagInfo describes a data object.
+// MinLength and MaxLength are in bytes, a LengthMultiple other than 0 requires
+// the length to be a multiple of it.
+type TagInfo struct {
+	Tag            string
+	Name           string
+	Format         Format
+	MinLength      int
+	MaxLength      int
+	LengthMultiple int
+}
+
+var tags = map[string]TagInfo{}
+
+func init() {
+	for _, info := range []TagInfo{
+		{IDPayloadFormatIndicator, "Payload Format Indicator", FormatAN, 5, 5, 0},
+		{IDApplicationTemplate, "Application Template", FormatB, 0, 0xFFFF, 0},
+		{IDCommonDataTemplate, "Common Data Template", FormatB, 0, 0xFFFF, 0},
+		{IDApplicationSpecificTransparentTemplate, "Application Specific Transparent Template", FormatB, 0, 0xFFFF, 0},
+		{IDCommonDataTransparentTemplate, "Common Data Transparent Template", FormatB, 0, 0xFFFF, 0},
+		{TagApplicationDefinitionFileName, "Application Definition File (ADF) Name", FormatB, 5, 16, 0},
+		{TagApplicationLabel, "Application Label", FormatANS, 1, 16, 0},
+		{TagTrack2EquivalentData, "Track 2 Equivalent Data", FormatB, 1, 19, 0},
+		{TagApplicationPAN, "Application PAN", FormatCN, 1, 10, 0},
+		{TagCardholderName, "Cardholder Name", FormatANS, 2, 26, 0},
+		{TagLanguagePreference, "Language Preference", FormatA, 2, 8, 2},
+		{TagIssuerURL, "Issuer URL", FormatANS, 1, 0xFFFF, 0},
+		{TagApplicationVersionNumber, "Application Version Number", FormatB, 2, 2, 0},
+		{TagIssuerApplicationData, "Issuer Application Data", FormatB, 1, 32, 0},
+		{TagTokenRequestorID, "Token Requestor ID", FormatN, 6, 6, 0},
+		{TagPaymentAccountReference, "Payment Account Reference", FormatAN, 29, 29, 0},
+		{TagLast4DigitsOfPAN, "Last 4 Digits of PAN", FormatN, 2, 2, 0},
+		{TagApplicationCryptogram, "Application Cryptogram", FormatB, 8, 8, 0},
+		{TagApplicationTransactionCounter, "Application Transaction Counter", FormatB, 2, 2, 0},
+		{TagUnpredictableNumber, "Unpredictable Number", FormatB, 4, 4, 0},
+		{TagApplicationInterchangeProfile, "Application Interchange Profile", FormatB, 2, 2, 0},
+		{TagApplicationExpirationDate, "Application Expiration Date", FormatN, 3, 3, 0},
+		{TagPANSequenceNumber, "Application PAN Sequence Number", FormatN, 1, 1, 0},
+		{TagApplicationUsageControl, "Application Usage Control", FormatB, 2, 2, 0},
+		{TagCryptogramInformationData, "Cryptogram Information Data", FormatB, 1, 1, 0},
+		{TagCVMResults, "Cardholder Verification Method (CVM) Results", FormatB, 3, 3, 0},
+		{TagThirdPartyData, "Third Party Data", FormatB, 5, 32, 0},
+	} {
+		tags[info.Tag] = info
+	}
+}
+
+// LookupTag returns the dictionary entry of a tag.
+func LookupTag(tag string) (TagInfo, bool) {
+	info, ok := tags[strings.ToUpper(tag)]
+	return info, ok
+}
+
+// TagName returns the name of a tag, or "" if it is not known.
+func TagName(tag string) string {
+	info, _ := LookupTag(tag)
+	return info.Name
+}
+
+// Text reports whether values of the format are characters rather than bytes.
+func (f Format) Text() bool {
+	return f == FormatA || f == FormatAN || f == FormatANS
+}
+
+// Validate checks value against the length and format rules of the tag.
+func (i TagInfo) Validate(value []byte) error {
+	if len(value) < i.MinLength || len(value) > i.MaxLength {
+		if i.MinLength == i.MaxLength {
+			return fmt.Errorf("%s (%s) should be %d bytes, length: %d", i.Name, i.Tag, i.MinLength, len(value))
+		}
+		return fmt.Errorf("%s (%s) should be %d to %d bytes, length: %d", i.Name, i.Tag, i.MinLength, i.MaxLength, len(value))
+	}
+	if i.LengthMultiple > 0 && len(value)%i.LengthMultiple != 0 {
+		return fmt.Errorf("%s (%s) length should be a multiple of %d, length: %d", i.Name, i.Tag, i.LengthMultiple, len(value))
+	}
+	if !i.Format.valid(value) {
+		return fmt.Errorf("%s (%s) should be format %s, value: %X", i.Name, i.Tag, i.Format, value)
+	}
+	return nil
+}
+
+// ValidateTag checks value against the dictionary entry of tag. Unknown tags are valid.
+func ValidateTag(tag string, value []byte) error {
+	info, ok := LookupTag(tag)
+	if !ok {
+		return nil
+	}
+	return info.Validate(value)
+}
+
+func (f Format) valid(value []byte) bool {
+	switch f {
+	case FormatA, FormatAN, FormatANS:
+		for _, c := range value {
+			alpha := c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+			numeric := c >= '0' && c <= '9'
+			switch {
+			case f == FormatA && !alpha,
+				f == FormatAN && !alpha && !numeric,
+				f == FormatANS && (c < 0x20 || c > 0x7E):
+				return false
+			}
+		}
+	case FormatN:
+		for _, c := range value {
+			if c>>4 > 9 || c&0x0F > 9 {
+				return false
+			}
+		}
+	case FormatCN:
+		padding := false
+		for _, c := range value {
+			for _, nibble := range []byte{c >> 4, c & 0x0F} {
+				switch {
+				case nibble == 0x0F:
+					padding = true
+				case nibble > 9 || padding:
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// isConstructed reports whether the data object of tag is a template,
+// from the constructed bit of its first byte.
+func isConstructed(tag string) bool {
+	var b byte
+	if _, err := fmt.Sscanf(tag, "%02X", &b); err != nil {
+		return false
+	}
+	return b&0x20 != 0
+}
@@ -0,0 +1,163 @@
+package cpm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateTag(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     string
+		value   []byte
+		wantErr bool
+	}{
+		{
+			name:  "PAN",
+			tag:   TagApplicationPAN,
+			value: []byte{0x12, 0x34, 0x56, 0x78, 0x90, 0x12, 0x34, 0x58},
+		},
+		{
+			name:  "PAN with padding",
+			tag:   TagApplicationPAN,
+			value: []byte{0x12, 0x34, 0x56, 0x78, 0x90, 0x12, 0x34, 0x5F},
+		},
+		{
+			name:    "PAN with digits after padding",
+			tag:     TagApplicationPAN,
+			value:   []byte{0x12, 0x34, 0x56, 0x78, 0x90, 0x12, 0x34, 0xF5},
+			wantErr: true,
+		},
+		{
+			name:    "PAN with hex digit",
+			tag:     TagApplicationPAN,
+			value:   []byte{0x12, 0x3A},
+			wantErr: true,
+		},
+		{
+			name:    "PAN too long",
+			tag:     TagApplicationPAN,
+			value:   make([]byte, 11),
+			wantErr: true,
+		},
+		{
+			name:  "language preference",
+			tag:   TagLanguagePreference,
+			value: []byte("ruesdeen"),
+		},
+		{
+			name:    "language preference odd length",
+			tag:     TagLanguagePreference,
+			value:   []byte("rue"),
+			wantErr: true,
+		},
+		{
+			name:    "language preference not alpha",
+			tag:     TagLanguagePreference,
+			value:   []byte("r1"),
+			wantErr: true,
+		},
+		{
+			name:    "language preference too long",
+			tag:     TagLanguagePreference,
+			value:   []byte("ruesdeenfr"),
+			wantErr: true,
+		},
+		{
+			name:  "expiration date",
+			tag:   TagApplicationExpirationDate,
+			value: []byte{0x25, 0x12, 0x31},
+		},
+		{
+			name:    "expiration date not numeric",
+			tag:     "5f24",
+			value:   []byte{0x25, 0x1C, 0x31},
+			wantErr: true,
+		},
+		{
+			name:    "payment account reference not alphanumeric",
+			tag:     TagPaymentAccountReference,
+			value:   []byte("5001A1B2C3D4E5F6G7H8I9J0K1L-"),
+			wantErr: true,
+		},
+		{
+			name:    "cryptogram length",
+			tag:     TagApplicationCryptogram,
+			value:   make([]byte, 7),
+			wantErr: true,
+		},
+		{
+			name:  "unknown tag",
+			tag:   "DF01",
+			value: []byte{0xFF},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateTag(tt.tag, tt.value); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateTag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBERTLV_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tlv     BERTLV
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			tlv:  sampleEMVQR().CommonDataTemplates[0].BERTLV,
+		},
+		{
+			name:    "not hex",
+			tlv:     BERTLV{DataApplicationCryptogram: "584FD385FA234BCZ"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid field",
+			tlv:     BERTLV{DataLanguagePreference: "rue"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid additional data object",
+			tlv:     BERTLV{Additional: []TLV{{Tag: TagCryptogramInformationData, Value: []byte{0x80, 0x00}}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.tlv.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("BERTLV.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseEMVQR_additionalTags(t *testing.T) {
+	qr := sampleEMVQR()
+	cdtt := &qr.CommonDataTemplates[0].CommonDataTransparentTemplates[0]
+	cdtt.DataCryptogramInformationData = "80"
+	cdtt.DataApplicationInterchangeProfile = "1980"
+	cdtt.DataCVMResults = "1F0302"
+	cdtt.DataPaymentAccountReference = "353030314131423243334434453546364737483849394A304B314C324D" // 5001A1B2C3D4E5F6G7H8I9J0K1L2M
+	cdtt.Additional = []TLV{{Tag: "DF01", Value: []byte{0x01, 0x02}}, {Tag: "9F7C", Value: []byte{0xAA}}}
+	qr.CommonDataTemplates[0].DataPANSequenceNumber = "01"
+	qr.CommonDataTemplates[0].DataApplicationExpirationDate = "251231"
+	payload, err := qr.GeneratePayload()
+	if err != nil {
+		t.Fatalf("EMVQR.GeneratePayload() error = %v", err)
+	}
+	got, err := ParseEMVQR(payload)
+	if err != nil {
+		t.Fatalf("ParseEMVQR() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, qr) {
+		t.Errorf("ParseEMVQR() = %v, want %v", got, qr)
+	}
+	if err := got.CommonDataTemplates[0].CommonDataTransparentTemplates[0].Validate(); err != nil {
+		t.Errorf("BERTLV.Validate() error = %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package cpm
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Token is a payment token, which the payload carries in place of the PAN it
+// stands for (the funding PAN).
+type Token struct {
+	PAN                     string // token PAN, 8 to 19 digits
+	TokenRequestorID        string // 9F19, 11 digits
+	PaymentAccountReference string // 9F24, 29 alphanumeric characters
+}
+
+// Validate ...
+func (tk Token) Validate() error {
+	if err := validatePAN("Token PAN", tk.PAN); err != nil {
+		return err
+	}
+	if len(tk.TokenRequestorID) != 11 || !isDigits(tk.TokenRequestorID) {
+		return fmt.Errorf("TokenRequestorID should be 11 digits, TokenRequestorID: %s", tk.TokenRequestorID)
+	}
+	if len(tk.PaymentAccountReference) != 29 || !FormatAN.valid([]byte(tk.PaymentAccountReference)) {
+		return fmt.Errorf("PaymentAccountReference should be 29 alphanumeric characters, PaymentAccountReference: %s", tk.PaymentAccountReference)
+	}
+	return nil
+}
+
+// SetToken stores the token in the Application PAN (5A), Token Requestor ID
+// (9F19) and Payment Account Reference (9F24), and the last 4 digits of the
+// funding PAN in 9F25.
+func (t *BERTLV) SetToken(tk Token, fundingPAN string) error {
+	if err := tk.Validate(); err != nil {
+		return err
+	}
+	if err := validatePAN("Funding PAN", fundingPAN); err != nil {
+		return err
+	}
+	t.DataApplicationPAN = tk.PAN
+	if len(tk.PAN)%2 != 0 {
+		t.DataApplicationPAN += "F"
+	}
+	// n 11 in 6 bytes, right justified
+	t.DataTokenRequestorID = "0" + tk.TokenRequestorID
+	t.DataPaymentAccountReference = strings.ToUpper(toHex(tk.PaymentAccountReference))
+	t.DataLast4DigitsOfPAN = fundingPAN[len(fundingPAN)-4:]
+	return nil
+}
+
+// Token returns the token of the template, or nil if it has no Token Requestor ID.
+func (t *BERTLV) Token() (*Token, error) {
+	if t.DataTokenRequestorID == "" {
+		return nil, nil
+	}
+	if len(t.DataTokenRequestorID) != 12 || t.DataTokenRequestorID[0] != '0' {
+		return nil, fmt.Errorf("DataTokenRequestorID should be 11 digits with a leading 0, DataTokenRequestorID: %s", t.DataTokenRequestorID)
+	}
+	par, err := hex.DecodeString(t.DataPaymentAccountReference)
+	if err != nil {
+		return nil, fmt.Errorf("DataPaymentAccountReference should be hex, DataPaymentAccountReference: %s", t.DataPaymentAccountReference)
+	}
+	tk := &Token{
+		PAN:                     strings.TrimRight(strings.ToUpper(t.DataApplicationPAN), "F"),
+		TokenRequestorID:        t.DataTokenRequestorID[1:],
+		PaymentAccountReference: string(par),
+	}
+	if err := tk.Validate(); err != nil {
+		return nil, err
+	}
+	return tk, nil
+}
+
+// Detokenize returns the funding PAN of the token of the template from the
+// vault, and checks it against the Last 4 Digits of PAN (9F25).
+func (t *BERTLV) Detokenize(vault TokenVault) (string, error) {
+	tk, err := t.Token()
+	if err != nil {
+		return "", err
+	}
+	if tk == nil {
+		return "", errors.New("DataTokenRequestorID is mandatory for a token")
+	}
+	fundingPAN, err := vault.Detokenize(*tk)
+	if err != nil {
+		return "", err
+	}
+	if err := validatePAN("Funding PAN", fundingPAN); err != nil {
+		return "", err
+	}
+	if t.DataLast4DigitsOfPAN != "" && t.DataLast4DigitsOfPAN != fundingPAN[len(fundingPAN)-4:] {
+		return "", fmt.Errorf("DataLast4DigitsOfPAN should match the funding PAN, DataLast4DigitsOfPAN: %s", t.DataLast4DigitsOfPAN)
+	}
+	return fundingPAN, nil
+}
+
+// ErrTokenNotFound is returned by a TokenVault for unknown tokens.
+var ErrTokenNotFound = errors.New("cpm: token not found")
+
+// TokenVault maps tokens back to their funding PANs.
+type TokenVault interface {
+	Detokenize(tk Token) (string, error)
+}
+
+// MemoryTokenVault is a TokenVault for tests.
+type MemoryTokenVault struct {
+	mu     sync.RWMutex
+	tokens map[Token]string
+}
+
+// NewMemoryTokenVault ...
+func NewMemoryTokenVault() *MemoryTokenVault {
+	return &MemoryTokenVault{
+		tokens: make(map[Token]string),
+	}
+}
+
+// Add ...
+func (v *MemoryTokenVault) Add(tk Token, fundingPAN string) error {
+	if err := tk.Validate(); err != nil {
+		return err
+	}
+	if err := validatePAN("Funding PAN", fundingPAN); err != nil {
+		return err
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.tokens[tk] = fundingPAN
+	return nil
+}
+
+// Detokenize ...
+func (v *MemoryTokenVault) Detokenize(tk Token) (string, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	fundingPAN, ok := v.tokens[tk]
+	if !ok {
+		return "", ErrTokenNotFound
+	}
+	return fundingPAN, nil
+}
+
+func validatePAN(name, pan string) error {
+	if len(pan) < 8 || len(pan) > 19 || !isDigits(pan) {
+		return fmt.Errorf("%s should be 8 to 19 digits, %s: %s", name, name, pan)
+	}
+	return nil
+}
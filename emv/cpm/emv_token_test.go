@@ -0,0 +1,124 @@
+package cpm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestBERTLV_SetToken(t *testing.T) {
+	token := Token{PAN: "4895370012345678901", TokenRequestorID: "40010030273", PaymentAccountReference: "V0010013018329182347812364851"}
+	tests := []struct {
+		name       string
+		token      Token
+		fundingPAN string
+		want       BERTLV
+		wantErr    bool
+	}{
+		{
+			name:       "token",
+			token:      token,
+			fundingPAN: "4111111111111111",
+			want: BERTLV{
+				DataApplicationPAN:          "4895370012345678901F",
+				DataTokenRequestorID:        "040010030273",
+				DataPaymentAccountReference: "5630303130303133303138333239313832333437383132333634383531",
+				DataLast4DigitsOfPAN:        "1111",
+			},
+		},
+		{
+			name:       "short TRID",
+			token:      Token{PAN: token.PAN, TokenRequestorID: "4001003027", PaymentAccountReference: token.PaymentAccountReference},
+			fundingPAN: "4111111111111111",
+			wantErr:    true,
+		},
+		{
+			name:       "PAR with a symbol",
+			token:      Token{PAN: token.PAN, TokenRequestorID: token.TokenRequestorID, PaymentAccountReference: "V001001301832918234781236485-"},
+			fundingPAN: "4111111111111111",
+			wantErr:    true,
+		},
+		{
+			name:       "short PAN",
+			token:      Token{PAN: "1234567", TokenRequestorID: token.TokenRequestorID, PaymentAccountReference: token.PaymentAccountReference},
+			fundingPAN: "4111111111111111",
+			wantErr:    true,
+		},
+		{
+			name:       "bad funding PAN",
+			token:      token,
+			fundingPAN: "4111-1111",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got BERTLV
+			err := got.SetToken(tt.token, tt.fundingPAN)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BERTLV.SetToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BERTLV.SetToken() = %+v, want %+v", got, tt.want)
+			}
+			if err := got.Validate(); err != nil {
+				t.Errorf("BERTLV.Validate() error = %v", err)
+			}
+			tk, err := got.Token()
+			if err != nil || !reflect.DeepEqual(*tk, tt.token) {
+				t.Errorf("BERTLV.Token() = %+v, %v, want %+v", tk, err, tt.token)
+			}
+		})
+	}
+}
+
+func TestBERTLV_Detokenize(t *testing.T) {
+	token := Token{PAN: "4895370012345671", TokenRequestorID: "40010030273", PaymentAccountReference: "V0010013018329182347812364851"}
+	vault := NewMemoryTokenVault()
+	if err := vault.Add(token, "4111111111111111"); err != nil {
+		t.Fatalf("MemoryTokenVault.Add() error = %v", err)
+	}
+	var tlv BERTLV
+	if err := tlv.SetToken(token, "4111111111111111"); err != nil {
+		t.Fatalf("BERTLV.SetToken() error = %v", err)
+	}
+	qr := sampleEMVQR()
+	qr.CommonDataTemplates[0].BERTLV = tlv
+	payload, err := Encode(qr)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	decoded, err := Decode(payload)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	cdt := decoded.CommonDataTemplates[0]
+	if pan, err := cdt.Detokenize(vault); err != nil || pan != "4111111111111111" {
+		t.Errorf("BERTLV.Detokenize() = %s, %v, want 4111111111111111", pan, err)
+	}
+
+	cdt.DataLast4DigitsOfPAN = "2222"
+	if _, err := cdt.Detokenize(vault); err == nil {
+		t.Errorf("BERTLV.Detokenize() with other last 4 digits should fail")
+	}
+	cdt.DataApplicationPAN = "4895370012345679"
+	if _, err := cdt.Detokenize(vault); !errors.Is(err, ErrTokenNotFound) {
+		t.Errorf("BERTLV.Detokenize() error = %v, want %v", err, ErrTokenNotFound)
+	}
+	if _, err := (&BERTLV{}).Detokenize(vault); err == nil {
+		t.Errorf("BERTLV.Detokenize() without a token should fail")
+	}
+	if _, err := tlv.Detokenize(shortTokenVault{}); err == nil {
+		t.Errorf("BERTLV.Detokenize() with a short funding PAN should fail")
+	}
+}
+
+// shortTokenVault returns a funding PAN too short to be one.
+type shortTokenVault struct{}
+
+func (shortTokenVault) Detokenize(tk Token) (string, error) {
+	return "111", nil
+}
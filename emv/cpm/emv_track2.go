@@ -0,0 +1,117 @@
+package cpm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Track2 is the Track 2 Equivalent Data (57): the PAN, the separator D, the
+// expiry date, the service code and the discretionary data, one digit per
+// nibble and padded with F to a whole number of bytes.
+type Track2 struct {
+	PAN               string // up to 19 digits
+	Expiry            string // YYMM
+	ServiceCode       string // 3 digits
+	DiscretionaryData string // digits
+}
+
+// track2Separator is the field separator between the PAN and the expiry date.
+const track2Separator = "D"
+
+// ParseTrack2 parses Track 2 Equivalent Data from hex, as held in DataTrack2EquivalentData.
+func ParseTrack2(value string) (*Track2, error) {
+	s := strings.ToUpper(value)
+	if len(s)%2 != 0 {
+		return nil, fmt.Errorf("Track2 should be even-length hex, Track2: %s", value)
+	}
+	if strings.HasSuffix(s, "F") {
+		s = s[:len(s)-1]
+	}
+	i := strings.Index(s, track2Separator)
+	if i < 0 {
+		return nil, fmt.Errorf("Track2 should contain the separator %s, Track2: %s", track2Separator, value)
+	}
+	rest := s[i+1:]
+	if len(rest) < 7 {
+		return nil, fmt.Errorf("Track2 should contain an expiry date and a service code after the separator, Track2: %s", value)
+	}
+	t := &Track2{
+		PAN:               s[:i],
+		Expiry:            rest[:4],
+		ServiceCode:       rest[4:7],
+		DiscretionaryData: rest[7:],
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// String returns the Track 2 Equivalent Data in hex, padded with F.
+func (t Track2) String() string {
+	s := t.PAN + track2Separator + t.Expiry + t.ServiceCode + t.DiscretionaryData
+	if len(s)%2 != 0 {
+		s += "F"
+	}
+	return s
+}
+
+// Validate checks the fields of the Track 2 Equivalent Data.
+func (t Track2) Validate() error {
+	if t.PAN == "" || len(t.PAN) > 19 || !isDigits(t.PAN) {
+		return fmt.Errorf("Track2 PAN should be 1 to 19 digits, PAN: %s", t.PAN)
+	}
+	if len(t.Expiry) != 4 || !isDigits(t.Expiry) || t.Expiry[2:] < "01" || t.Expiry[2:] > "12" {
+		return fmt.Errorf("Track2 Expiry should be YYMM, Expiry: %s", t.Expiry)
+	}
+	if len(t.ServiceCode) != 3 || !isDigits(t.ServiceCode) {
+		return fmt.Errorf("Track2 ServiceCode should be 3 digits, ServiceCode: %s", t.ServiceCode)
+	}
+	if !isDigits(t.DiscretionaryData) {
+		return fmt.Errorf("Track2 DiscretionaryData should be digits, DiscretionaryData: %s", t.DiscretionaryData)
+	}
+	if len(t.String()) > 19*2 {
+		return errors.New("Track2 should be at most 19 bytes")
+	}
+	return nil
+}
+
+// Track2 returns the parsed Track 2 Equivalent Data, or nil if it is not set.
+func (t *BERTLV) Track2() (*Track2, error) {
+	if t.DataTrack2EquivalentData == "" {
+		return nil, nil
+	}
+	return ParseTrack2(t.DataTrack2EquivalentData)
+}
+
+// SetTrack2 validates v and stores it as the Track 2 Equivalent Data.
+func (t *BERTLV) SetTrack2(v Track2) error {
+	if err := v.Validate(); err != nil {
+		return err
+	}
+	t.DataTrack2EquivalentData = v.String()
+	return nil
+}
+
+// validateTrack2 checks the Track 2 Equivalent Data and that its PAN matches the Application PAN (5A).
+func (t *BERTLV) validateTrack2() error {
+	track2, err := t.Track2()
+	if err != nil || track2 == nil || t.DataApplicationPAN == "" {
+		return err
+	}
+	pan := strings.TrimRight(strings.ToUpper(t.DataApplicationPAN), "F")
+	if track2.PAN != pan {
+		return fmt.Errorf("Track2 PAN should match ApplicationPAN (%s), PAN: %s, ApplicationPAN: %s", TagApplicationPAN, track2.PAN, pan)
+	}
+	return nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
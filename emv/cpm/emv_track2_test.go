@@ -0,0 +1,140 @@
+package cpm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTrack2(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    *Track2
+		wantErr bool
+	}{
+		{
+			name:  "padded",
+			value: "4761739001010119D22122011143804400000F",
+			want:  &Track2{PAN: "4761739001010119", Expiry: "2212", ServiceCode: "201", DiscretionaryData: "1143804400000"},
+		},
+		{
+			name:  "not padded",
+			value: "4761739001010119D2212201",
+			want:  &Track2{PAN: "4761739001010119", Expiry: "2212", ServiceCode: "201"},
+		},
+		{
+			name:  "lower case",
+			value: "4761739001010119d221220112",
+			want:  &Track2{PAN: "4761739001010119", Expiry: "2212", ServiceCode: "201", DiscretionaryData: "12"},
+		},
+		{
+			name:    "odd length",
+			value:   "4761739001010119D221220",
+			wantErr: true,
+		},
+		{
+			name:    "no separator",
+			value:   "47617390010101192212201F",
+			wantErr: true,
+		},
+		{
+			name:    "no service code",
+			value:   "4761739001010119D2212F",
+			wantErr: true,
+		},
+		{
+			name:    "bad month",
+			value:   "4761739001010119D2213201",
+			wantErr: true,
+		},
+		{
+			name:    "hex digit in discretionary data",
+			value:   "4761739001010119D2212201AB",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTrack2(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseTrack2() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTrack2() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrack2_String(t *testing.T) {
+	tests := []struct {
+		name   string
+		track2 Track2
+		want   string
+	}{
+		{
+			name:   "padded",
+			track2: Track2{PAN: "4761739001010119", Expiry: "2212", ServiceCode: "201", DiscretionaryData: "1143804400000"},
+			want:   "4761739001010119D22122011143804400000F",
+		},
+		{
+			name:   "not padded",
+			track2: Track2{PAN: "4761739001010119", Expiry: "2212", ServiceCode: "201"},
+			want:   "4761739001010119D2212201",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.track2.String(); got != tt.want {
+				t.Errorf("Track2.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBERTLV_Track2(t *testing.T) {
+	track2 := Track2{PAN: "4761739001010119", Expiry: "2212", ServiceCode: "201", DiscretionaryData: "1"}
+	tests := []struct {
+		name    string
+		pan     string
+		wantErr bool
+	}{
+		{
+			name: "no PAN",
+		},
+		{
+			name: "matching PAN",
+			pan:  "4761739001010119",
+		},
+		{
+			name: "matching padded PAN",
+			pan:  "4761739001010119FF",
+		},
+		{
+			name:    "truncated PAN",
+			pan:     "476173900101011F",
+			wantErr: true,
+		},
+		{
+			name:    "different PAN",
+			pan:     "4761739001010127",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlv := &BERTLV{DataApplicationPAN: tt.pan}
+			if err := tlv.SetTrack2(track2); err != nil {
+				t.Fatalf("BERTLV.SetTrack2() error = %v", err)
+			}
+			got, err := tlv.Track2()
+			if err != nil || !reflect.DeepEqual(*got, track2) {
+				t.Errorf("BERTLV.Track2() = %+v, %v, want %+v", got, err, track2)
+			}
+			if err := tlv.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("BERTLV.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package cpm
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PayloadFormatIndicatorCPV01 is the Payload Format Indicator of EMVCo CPM version 01.
+const PayloadFormatIndicatorCPV01 = "CPV01"
+
+// transparentTemplateMandatoryTags are the data objects the issuer needs to
+// verify the cryptogram of a transparent template.
+var transparentTemplateMandatoryTags = []string{
+	TagApplicationCryptogram,
+	TagApplicationTransactionCounter,
+	TagIssuerApplicationData,
+}
+
+// Validate ...
+func (c *EMVQR) Validate() error {
+	if c.DataPayloadFormatIndicator != PayloadFormatIndicatorCPV01 {
+		return fmt.Errorf("DataPayloadFormatIndicator should be \"%s\", DataPayloadFormatIndicator: %s", PayloadFormatIndicatorCPV01, c.DataPayloadFormatIndicator)
+	}
+	if len(c.ApplicationTemplates) == 0 {
+		return errors.New("ApplicationTemplates is mandatory")
+	}
+	for i, t := range c.ApplicationTemplates {
+		path := fmt.Sprintf("%s[%d]", IDApplicationTemplate, i)
+		if t.DataApplicationDefinitionFileName == "" {
+			return fmt.Errorf("%s: DataApplicationDefinitionFileName (%s) is mandatory", path, TagApplicationDefinitionFileName)
+		}
+		own := networks(t)
+		if err := validateTemplate(path, t.BERTLV, false, own); err != nil {
+			return err
+		}
+		for j, tt := range t.ApplicationSpecificTransparentTemplates {
+			if err := validateTemplate(fmt.Sprintf("%s > %s[%d]", path, IDApplicationSpecificTransparentTemplate, j), tt.BERTLV, true, own); err != nil {
+				return err
+			}
+		}
+	}
+	// common data applies to every application
+	all := networks(c.ApplicationTemplates...)
+	for i, t := range c.CommonDataTemplates {
+		path := fmt.Sprintf("%s[%d]", IDCommonDataTemplate, i)
+		if err := validateTemplate(path, t.BERTLV, false, all); err != nil {
+			return err
+		}
+		for j, tt := range t.CommonDataTransparentTemplates {
+			if err := validateTemplate(fmt.Sprintf("%s > %s[%d]", path, IDCommonDataTransparentTemplate, j), tt.BERTLV, true, all); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateTemplate(path string, t BERTLV, transparent bool, networks []string) error {
+	if err := t.Validate(); err != nil {
+		return fmt.Errorf("%s: %s", path, err)
+	}
+	if err := validateBrand(path, t, networks); err != nil {
+		return err
+	}
+	for _, tlv := range t.Additional {
+		switch tlv.Tag {
+		case IDPayloadFormatIndicator, IDApplicationTemplate, IDCommonDataTemplate:
+			return fmt.Errorf("%s: %s should not be inside a template", path, tlv.Tag)
+		case IDApplicationSpecificTransparentTemplate:
+			return fmt.Errorf("%s: %s should be inside %s", path, tlv.Tag, IDApplicationTemplate)
+		case IDCommonDataTransparentTemplate:
+			return fmt.Errorf("%s: %s should be inside %s", path, tlv.Tag, IDCommonDataTemplate)
+		}
+	}
+	if !transparent {
+		return nil
+	}
+	for _, tag := range transparentTemplateMandatoryTags {
+		if !t.has(tag) {
+			return fmt.Errorf("%s: %s (%s) is mandatory in a transparent template", path, TagName(tag), tag)
+		}
+	}
+	return nil
+}
+
+// has reports whether t holds a data object with tag.
+func (t *BERTLV) has(tag string) bool {
+	for _, f := range t.fields() {
+		if f.tag == tag {
+			return *f.value != ""
+		}
+	}
+	for _, tlv := range t.Additional {
+		if tlv.Tag == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package cpm
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestEMVQR_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(qr *EMVQR)
+		wantErr bool
+	}{
+		{
+			name:   "ok",
+			modify: func(qr *EMVQR) {},
+		},
+		{
+			name:    "payload format indicator",
+			modify:  func(qr *EMVQR) { qr.DataPayloadFormatIndicator = "CPV02" },
+			wantErr: true,
+		},
+		{
+			name:    "no application template",
+			modify:  func(qr *EMVQR) { qr.ApplicationTemplates = nil },
+			wantErr: true,
+		},
+		{
+			name:    "no ADF name",
+			modify:  func(qr *EMVQR) { qr.ApplicationTemplates[1].DataApplicationDefinitionFileName = "" },
+			wantErr: true,
+		},
+		{
+			name:    "short AID",
+			modify:  func(qr *EMVQR) { qr.ApplicationTemplates[0].DataApplicationDefinitionFileName = "A0000000" },
+			wantErr: true,
+		},
+		{
+			name: "long AID",
+			modify: func(qr *EMVQR) {
+				qr.ApplicationTemplates[0].DataApplicationDefinitionFileName = "A0000000555555555555555555555555FF"
+			},
+			wantErr: true,
+		},
+		{
+			name:    "odd length hex",
+			modify:  func(qr *EMVQR) { qr.CommonDataTemplates[0].DataApplicationPAN = "123456789012345" },
+			wantErr: true,
+		},
+		{
+			name: "transparent template in the wrong template",
+			modify: func(qr *EMVQR) {
+				qr.ApplicationTemplates[0].Additional = []TLV{{Tag: IDCommonDataTransparentTemplate, Value: []byte{0x9F, 0x36, 0x02, 0x00, 0x01}}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing cryptogram",
+			modify: func(qr *EMVQR) {
+				qr.CommonDataTemplates[0].CommonDataTransparentTemplates[0].DataApplicationCryptogram = ""
+			},
+			wantErr: true,
+		},
+		{
+			name: "application specific transparent template",
+			modify: func(qr *EMVQR) {
+				qr.ApplicationTemplates[0].ApplicationSpecificTransparentTemplates = []ApplicationSpecificTransparentTemplate{
+					{BERTLV: BERTLV{DataApplicationTransactionCounter: "0001"}},
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "PAN of the network of an application",
+			modify: func(qr *EMVQR) {
+				qr.ApplicationTemplates[0].DataApplicationDefinitionFileName = "A0000000031010"
+				qr.CommonDataTemplates[0].DataApplicationPAN = "4111111111111111"
+			},
+		},
+		{
+			name: "PAN failing the Luhn check",
+			modify: func(qr *EMVQR) {
+				qr.CommonDataTemplates[0].DataApplicationPAN = "4111111111111112"
+			},
+			wantErr: true,
+		},
+		{
+			name: "PAN of the network of no application",
+			modify: func(qr *EMVQR) {
+				qr.ApplicationTemplates[0].DataApplicationDefinitionFileName = "A0000000031010"
+				qr.CommonDataTemplates[0].DataApplicationPAN = "5555555555554444"
+			},
+			wantErr: true,
+		},
+		{
+			name: "PAN of another network than its application",
+			modify: func(qr *EMVQR) {
+				qr.ApplicationTemplates[0].DataApplicationDefinitionFileName = "A0000000041010"
+				qr.ApplicationTemplates[0].DataApplicationPAN = "4111111111111111"
+				qr.CommonDataTemplates[0].DataApplicationPAN = ""
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qr := sampleEMVQR()
+			tt.modify(qr)
+			if err := qr.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("EMVQR.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, err := Encode(qr); (err != nil) != tt.wantErr {
+				t.Errorf("Encode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEMVQR_GeneratePayload_hexError(t *testing.T) {
+	qr := sampleEMVQR()
+	qr.CommonDataTemplates[0].CommonDataTransparentTemplates[0].DataApplicationCryptogram = "584FD385FA234BC"
+	_, err := qr.GeneratePayload()
+	want := "62[0] > 64[0]: value of 9F26 should be even-length hex, value: 584FD385FA234BC"
+	if err == nil || err.Error() != want {
+		t.Errorf("EMVQR.GeneratePayload() error = %v, want %v", err, want)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		wantErr bool
+	}{
+		{
+			name:    "ok",
+			payload: samplePayload,
+		},
+		{
+			name:    "invalid",
+			payload: base64.StdEncoding.EncodeToString([]byte{0x85, 0x05, 'C', 'P', 'V', '0', '1'}),
+			wantErr: true,
+		},
+		{
+			name:    "transparent template outside of its template",
+			payload: base64.StdEncoding.EncodeToString([]byte{0x85, 0x05, 'C', 'P', 'V', '0', '1', 0x64, 0x00}),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.payload); (err != nil) != tt.wantErr {
+				t.Errorf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// MaxATC is the last Application Transaction Counter a card can use; the
+// counter does not wrap around to 0.
+const MaxATC = 0xFFFF
+
+// ErrATCExhausted is returned once a card has used MaxATC.
+var ErrATCExhausted = errors.New("wallet: ATC exhausted")
+
+// Counter hands out the Application Transaction Counters of cards, from 1 up
+// to MaxATC. Implementations must be safe for concurrent use and never return
+// the same ATC twice for a card.
+type Counter interface {
+	Next(card string) (uint16, error)
+}
+
+// MemoryCounter ...
+type MemoryCounter struct {
+	mu   sync.Mutex
+	atcs map[string]uint16
+}
+
+// NewMemoryCounter ...
+func NewMemoryCounter() *MemoryCounter {
+	return &MemoryCounter{
+		atcs: make(map[string]uint16),
+	}
+}
+
+// Next ...
+func (c *MemoryCounter) Next(card string) (uint16, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	atc, err := next(c.atcs[card])
+	if err != nil {
+		return 0, err
+	}
+	c.atcs[card] = atc
+	return atc, nil
+}
+
+// FileCounter keeps the ATCs of cards in a JSON file, which is written before
+// an ATC is handed out so that a restarted wallet does not reuse it. It is
+// safe for concurrent use within a process; processes must not share a file.
+type FileCounter struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileCounter ...
+func NewFileCounter(path string) *FileCounter {
+	return &FileCounter{path: path}
+}
+
+// Next ...
+func (c *FileCounter) Next(card string) (uint16, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	atcs, err := c.load()
+	if err != nil {
+		return 0, err
+	}
+	atc, err := next(atcs[card])
+	if err != nil {
+		return 0, err
+	}
+	atcs[card] = atc
+	if err := c.save(atcs); err != nil {
+		return 0, err
+	}
+	return atc, nil
+}
+
+func (c *FileCounter) load() (map[string]uint16, error) {
+	atcs := make(map[string]uint16)
+	b, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return atcs, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &atcs); err != nil {
+		return nil, err
+	}
+	return atcs, nil
+}
+
+// save replaces the file through a temporary file, so that a crash leaves
+// either the old or the new counters.
+func (c *FileCounter) save(atcs map[string]uint16) error {
+	b, err := json.Marshal(atcs)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), c.path)
+}
+
+func next(atc uint16) (uint16, error) {
+	if atc == MaxATC {
+		return 0, ErrATCExhausted
+	}
+	return atc + 1, nil
+}
@@ -0,0 +1,102 @@
+// Package wallet generates CPM payloads for the cards of a wallet, with a
+// fresh Application Transaction Counter and Unpredictable Number each time.
+package wallet
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/dongri/emv-qrcode/emv/cpm"
+	"github.com/dongri/emv-qrcode/emv/cpm/crypto"
+)
+
+// Profile is a card of the wallet. Template is the payload of the card, whose
+// transparent templates get the ATC (9F36) and Unpredictable Number (9F37) of
+// each payload.
+type Profile struct {
+	ID       string // key of the card in the Counter
+	Template cpm.EMVQR
+}
+
+// Wallet ...
+type Wallet struct {
+	Counter Counter
+	// Rand is the source of Unpredictable Numbers, crypto/rand.Reader when nil.
+	Rand io.Reader
+	// Keys signs the payloads with crypto.Sign when set, so that the
+	// Application Cryptogram (9F26) matches the ATC and Unpredictable Number.
+	Keys        crypto.KeyProvider
+	Transaction crypto.Transaction
+}
+
+// UnpredictableNumber returns a 4 byte Unpredictable Number in hex, read from r.
+func UnpredictableNumber(r io.Reader) (string, error) {
+	b := make([]byte, 4)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%X", b), nil
+}
+
+// GeneratePayload returns a payload of the profile with the next ATC of the card.
+func (w *Wallet) GeneratePayload(p *Profile) (string, error) {
+	if p.ID == "" {
+		return "", errors.New("wallet: profile ID is mandatory")
+	}
+	if w.Counter == nil {
+		return "", errors.New("wallet: Counter is mandatory")
+	}
+	qr := clone(&p.Template)
+	tts := crypto.TransparentTemplates(qr)
+	if len(tts) == 0 {
+		return "", errors.New("wallet: profile has no transparent template")
+	}
+	r := w.Rand
+	if r == nil {
+		r = rand.Reader
+	}
+	un, err := UnpredictableNumber(r)
+	if err != nil {
+		return "", err
+	}
+	atc, err := w.Counter.Next(p.ID)
+	if err != nil {
+		return "", err
+	}
+	for _, tt := range tts {
+		tt.BERTLV.DataApplicationTransactionCounter = fmt.Sprintf("%04X", atc)
+		tt.BERTLV.DataUnpredictableNumber = un
+	}
+	if w.Keys != nil {
+		if err := crypto.Sign(w.Keys, w.Transaction, qr); err != nil {
+			return "", err
+		}
+	}
+	return cpm.Encode(qr)
+}
+
+// clone copies the templates of qr, so that the profile is left as is.
+func clone(qr *cpm.EMVQR) *cpm.EMVQR {
+	c := &cpm.EMVQR{DataPayloadFormatIndicator: qr.DataPayloadFormatIndicator}
+	for _, at := range qr.ApplicationTemplates {
+		at.Additional = append([]cpm.TLV(nil), at.Additional...)
+		at.ApplicationSpecificTransparentTemplates = append([]cpm.ApplicationSpecificTransparentTemplate(nil), at.ApplicationSpecificTransparentTemplates...)
+		for i := range at.ApplicationSpecificTransparentTemplates {
+			t := &at.ApplicationSpecificTransparentTemplates[i]
+			t.Additional = append([]cpm.TLV(nil), t.Additional...)
+		}
+		c.ApplicationTemplates = append(c.ApplicationTemplates, at)
+	}
+	for _, cdt := range qr.CommonDataTemplates {
+		cdt.Additional = append([]cpm.TLV(nil), cdt.Additional...)
+		cdt.CommonDataTransparentTemplates = append([]cpm.CommonDataTransparentTemplate(nil), cdt.CommonDataTransparentTemplates...)
+		for i := range cdt.CommonDataTransparentTemplates {
+			t := &cdt.CommonDataTransparentTemplates[i]
+			t.Additional = append([]cpm.TLV(nil), t.Additional...)
+		}
+		c.CommonDataTemplates = append(c.CommonDataTemplates, cdt)
+	}
+	return c
+}
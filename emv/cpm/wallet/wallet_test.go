@@ -0,0 +1,172 @@
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/dongri/emv-qrcode/emv/cpm"
+	"github.com/dongri/emv-qrcode/emv/cpm/crypto"
+)
+
+func newProfile() *Profile {
+	p := &Profile{ID: "card1"}
+	p.Template.DataPayloadFormatIndicator = cpm.PayloadFormatIndicatorCPV01
+	at := new(cpm.ApplicationTemplate)
+	at.DataApplicationDefinitionFileName = "A0000000555555"
+	p.Template.ApplicationTemplates = append(p.Template.ApplicationTemplates, *at)
+	cdt := new(cpm.CommonDataTemplate)
+	cdt.DataApplicationPAN = "1234567890123458"
+	cdtt := new(cpm.CommonDataTransparentTemplate)
+	cdtt.DataIssuerApplicationData = "06010A03000000"
+	cdtt.DataApplicationCryptogram = "584FD385FA234BCC"
+	cdt.CommonDataTransparentTemplates = append(cdt.CommonDataTransparentTemplates, *cdtt)
+	p.Template.CommonDataTemplates = append(p.Template.CommonDataTemplates, *cdt)
+	return p
+}
+
+func TestCounters(t *testing.T) {
+	tests := []struct {
+		name    string
+		counter func(t *testing.T) Counter
+	}{
+		{
+			name:    "memory",
+			counter: func(t *testing.T) Counter { return NewMemoryCounter() },
+		},
+		{
+			name: "file",
+			counter: func(t *testing.T) Counter {
+				return NewFileCounter(filepath.Join(t.TempDir(), "atc.json"))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.counter(t)
+			var wg sync.WaitGroup
+			seen := make(chan uint16, 50)
+			for i := 0; i < cap(seen); i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					atc, err := c.Next("card1")
+					if err != nil {
+						t.Errorf("Counter.Next() error = %v", err)
+					}
+					seen <- atc
+				}()
+			}
+			wg.Wait()
+			close(seen)
+			atcs := make(map[uint16]bool)
+			for atc := range seen {
+				if atc == 0 || atcs[atc] {
+					t.Errorf("Counter.Next() = %d, handed out twice or 0", atc)
+				}
+				atcs[atc] = true
+			}
+			if atc, err := c.Next("card2"); err != nil || atc != 1 {
+				t.Errorf("Counter.Next() of another card = %d, %v, want 1", atc, err)
+			}
+		})
+	}
+}
+
+func TestMemoryCounter_Wraparound(t *testing.T) {
+	c := NewMemoryCounter()
+	c.atcs["card1"] = MaxATC - 1
+	if atc, err := c.Next("card1"); err != nil || atc != MaxATC {
+		t.Fatalf("MemoryCounter.Next() = %d, %v, want %d", atc, err, MaxATC)
+	}
+	if _, err := c.Next("card1"); !errors.Is(err, ErrATCExhausted) {
+		t.Errorf("MemoryCounter.Next() error = %v, want %v", err, ErrATCExhausted)
+	}
+}
+
+func TestFileCounter_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "atc.json")
+	c := NewFileCounter(path)
+	for want := uint16(1); want <= 3; want++ {
+		if atc, err := c.Next("card1"); err != nil || atc != want {
+			t.Fatalf("FileCounter.Next() = %d, %v, want %d", atc, err, want)
+		}
+	}
+	// a restarted wallet continues after the last ATC
+	if atc, err := NewFileCounter(path).Next("card1"); err != nil || atc != 4 {
+		t.Errorf("FileCounter.Next() after restart = %d, %v, want 4", atc, err)
+	}
+}
+
+func TestUnpredictableNumber(t *testing.T) {
+	if un, err := UnpredictableNumber(bytes.NewReader([]byte{0x6D, 0x58, 0xEF, 0x13})); err != nil || un != "6D58EF13" {
+		t.Errorf("UnpredictableNumber() = %s, %v, want 6D58EF13", un, err)
+	}
+	if _, err := UnpredictableNumber(bytes.NewReader([]byte{0x6D})); err == nil {
+		t.Errorf("UnpredictableNumber() of a short read should fail")
+	}
+}
+
+func TestWallet_GeneratePayload(t *testing.T) {
+	w := &Wallet{
+		Counter: NewMemoryCounter(),
+		Rand:    bytes.NewReader([]byte{0x6D, 0x58, 0xEF, 0x13, 0x01, 0x02, 0x03, 0x04}),
+	}
+	p := newProfile()
+	for _, want := range []struct {
+		atc, un string
+	}{
+		{"0001", "6D58EF13"},
+		{"0002", "01020304"},
+	} {
+		payload, err := w.GeneratePayload(p)
+		if err != nil {
+			t.Fatalf("Wallet.GeneratePayload() error = %v", err)
+		}
+		qr, err := cpm.Decode(payload)
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		cdtt := qr.CommonDataTemplates[0].CommonDataTransparentTemplates[0]
+		if cdtt.DataApplicationTransactionCounter != want.atc || cdtt.DataUnpredictableNumber != want.un {
+			t.Errorf("Wallet.GeneratePayload() ATC, UN = %s, %s, want %s, %s", cdtt.DataApplicationTransactionCounter, cdtt.DataUnpredictableNumber, want.atc, want.un)
+		}
+	}
+	if got := p.Template.CommonDataTemplates[0].CommonDataTransparentTemplates[0].DataApplicationTransactionCounter; got != "" {
+		t.Errorf("Wallet.GeneratePayload() changed the profile, ATC: %s", got)
+	}
+
+	if _, err := (&Wallet{}).GeneratePayload(p); err == nil {
+		t.Errorf("Wallet.GeneratePayload() without a Counter should fail")
+	}
+	p.Template.CommonDataTemplates[0].CommonDataTransparentTemplates = nil
+	if _, err := w.GeneratePayload(p); err == nil {
+		t.Errorf("Wallet.GeneratePayload() without a transparent template should fail")
+	}
+}
+
+func TestWallet_GeneratePayload_Sign(t *testing.T) {
+	keys := crypto.NewSoftwareKeyStore()
+	mk := crypto.MasterKey{Algorithm: crypto.AlgorithmAES, Key: make([]byte, 16)}
+	if err := keys.Add(crypto.KeyID{PAN: "1234567890123458"}, mk); err != nil {
+		t.Fatalf("SoftwareKeyStore.Add() error = %v", err)
+	}
+	w := &Wallet{Counter: NewMemoryCounter(), Keys: keys}
+	v := &crypto.Verifier{Keys: keys, ATCs: crypto.NewMemoryATCTracker()}
+	for i := 0; i < 2; i++ {
+		payload, err := w.GeneratePayload(newProfile())
+		if err != nil {
+			t.Fatalf("Wallet.GeneratePayload() error = %v", err)
+		}
+		qr, err := cpm.Decode(payload)
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		results, err := v.Verify(crypto.Transaction{}, qr)
+		if err != nil || len(results) != 1 || !results[0].Valid() {
+			t.Errorf("Verifier.Verify() = %v, %v, want valid", results, err)
+		}
+	}
+}
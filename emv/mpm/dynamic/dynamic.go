@@ -0,0 +1,297 @@
+package dynamic
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dongri/emv-qrcode/emv/mpm"
+)
+
+// const ...
+const (
+	// DefaultExpiryLayout is the time layout used for the expiry value when ExpiryTag.Layout is empty.
+	DefaultExpiryLayout = "20060102150405"
+	// ReferenceLength is the length of generated reference labels (62.05 allows up to 25).
+	ReferenceLength = 20
+
+	maxReferenceLabelLength = 25
+	maxTerminalLabelLength  = 25
+	maxReferenceAttempts    = 8
+)
+
+// errors ...
+var (
+	ErrSessionNotFound     = errors.New("dynamic: session not found")
+	ErrSessionExpired      = errors.New("dynamic: session expired")
+	ErrDuplicateReference  = errors.New("dynamic: reference label already in use")
+	ErrReferenceNotPresent = errors.New("dynamic: payload has no reference label")
+)
+
+// ExpiryTag tells the generator where to write the expiry of a session.
+// Template is either the Additional Data Field Template ("62"), in which case ID
+// must be a Payment System Specific ID (50-99), or an Unreserved Template (80-99),
+// in which case ID is a context specific ID (01-99) and GloballyUniqueIdentifier
+// is written to sub ID "00".
+type ExpiryTag struct {
+	Template                 mpm.ID
+	ID                       mpm.ID
+	GloballyUniqueIdentifier string
+	Layout                   string
+}
+
+// Validate ...
+func (e *ExpiryTag) Validate() error {
+	if e.Template == mpm.IDAdditionalDataFieldTemplate {
+		within, err := e.ID.Between(mpm.AdditionalIDPaymentSystemSpecificTemplatesRangeStart, mpm.AdditionalIDPaymentSystemSpecificTemplatesRangeEnd)
+		if err != nil || !within {
+			return fmt.Errorf("dynamic: expiry ID should be within 50-99 in template 62, ID: %s", e.ID)
+		}
+		return nil
+	}
+	within, err := e.Template.Between(mpm.IDUnreservedTemplatesRangeStart, mpm.IDUnreservedTemplatesRangeEnd)
+	if err != nil || !within {
+		return fmt.Errorf("dynamic: expiry template should be 62 or within 80-99, template: %s", e.Template)
+	}
+	within, err = e.ID.Between(mpm.UnreservedTemplateIDContextSpecificDataStart, mpm.UnreservedTemplateIDContextSpecificDataEnd)
+	if err != nil || !within {
+		return fmt.Errorf("dynamic: expiry ID should be within 01-99 in template %s, ID: %s", e.Template, e.ID)
+	}
+	if e.GloballyUniqueIdentifier == "" {
+		return fmt.Errorf("dynamic: GloballyUniqueIdentifier is mandatory for template %s", e.Template)
+	}
+	return nil
+}
+
+func (e *ExpiryTag) layout() string {
+	if e.Layout == "" {
+		return DefaultExpiryLayout
+	}
+	return e.Layout
+}
+
+// Request ...
+type Request struct {
+	Amount        string        // (M) Transaction Amount (54)
+	TerminalLabel string        // (O) Terminal Label (62.07), the reference when empty; a given label is not checked for uniqueness
+	Reference     string        // (O) Reference Label (62.05), generated when empty
+	TTL           time.Duration // (O) overrides Generator.TTL when non-zero
+}
+
+// Session ...
+type Session struct {
+	Reference     string
+	TerminalLabel string
+	Amount        string
+	Payload       string
+	CreatedAt     time.Time
+	ExpiresAt     time.Time // zero when the session never expires
+}
+
+// Expired ...
+func (s *Session) Expired(now time.Time) bool {
+	if s.ExpiresAt.IsZero() {
+		return false
+	}
+	return !now.Before(s.ExpiresAt)
+}
+
+// Generator produces one-shot dynamic (01=12) payloads from a static template.
+type Generator struct {
+	Template *mpm.EMVQR
+	Store    Store
+	TTL      time.Duration    // zero means sessions never expire
+	Expiry   *ExpiryTag       // nil means the expiry is not written into the payload
+	Now      func() time.Time // defaults to time.Now
+}
+
+// NewGenerator ...
+func NewGenerator(template *mpm.EMVQR, store Store) *Generator {
+	return &Generator{
+		Template: template,
+		Store:    store,
+	}
+}
+
+// Generate ...
+func (g *Generator) Generate(req Request) (*Session, error) {
+	if g.Template == nil {
+		return nil, errors.New("dynamic: Template is mandatory")
+	}
+	if g.Store == nil {
+		return nil, errors.New("dynamic: Store is mandatory")
+	}
+	if req.Amount == "" {
+		return nil, errors.New("dynamic: Amount is mandatory")
+	}
+	if utf8.RuneCountInString(req.TerminalLabel) > maxTerminalLabelLength {
+		return nil, fmt.Errorf("dynamic: TerminalLabel should be at most %d characters, TerminalLabel: %s", maxTerminalLabelLength, req.TerminalLabel)
+	}
+	if g.Expiry != nil {
+		if err := g.Expiry.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	reference, err := g.reference(req.Reference)
+	if err != nil {
+		return nil, err
+	}
+
+	terminalLabel := req.TerminalLabel
+	if terminalLabel == "" {
+		// the reference is unique across sessions, so is a label derived from it
+		terminalLabel = reference
+	}
+
+	now := g.now()
+	session := &Session{
+		Reference:     reference,
+		TerminalLabel: terminalLabel,
+		Amount:        req.Amount,
+		CreatedAt:     now,
+	}
+	ttl := g.TTL
+	if req.TTL != 0 {
+		ttl = req.TTL
+	}
+	if ttl > 0 {
+		session.ExpiresAt = now.Add(ttl)
+	}
+
+	template, err := mpm.Marshal(g.Template)
+	if err != nil {
+		return nil, err
+	}
+	emvqr, err := mpm.ParseEMVQR(template)
+	if err != nil {
+		return nil, err
+	}
+	emvqr.SetPointOfInitiationMethod(mpm.PointOfInitiationMethodDynamic)
+	emvqr.SetTransactionAmount(session.Amount)
+	additional := emvqr.AdditionalDataFieldTemplate
+	if additional == nil {
+		additional = new(mpm.AdditionalDataFieldTemplate)
+	}
+	additional.SetReferenceLabel(session.Reference)
+	additional.SetTerminalLabel(session.TerminalLabel)
+	emvqr.SetAdditionalDataFieldTemplate(additional)
+	if g.Expiry != nil && !session.ExpiresAt.IsZero() {
+		if err := g.setExpiry(emvqr, session.ExpiresAt); err != nil {
+			return nil, err
+		}
+	}
+
+	payload, err := mpm.Encode(emvqr)
+	if err != nil {
+		return nil, err
+	}
+	session.Payload = payload
+	if err := g.Store.Save(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Lookup ...
+func (g *Generator) Lookup(reference string) (*Session, error) {
+	session, err := g.Store.Load(reference)
+	if err != nil {
+		return nil, err
+	}
+	if session.Expired(g.now()) {
+		return session, ErrSessionExpired
+	}
+	return session, nil
+}
+
+// LookupPayload finds the session of a payload by its Reference Label (62.05).
+func (g *Generator) LookupPayload(payload string) (*Session, error) {
+	emvqr, err := mpm.ParseEMVQR(payload)
+	if err != nil {
+		return nil, err
+	}
+	if emvqr.AdditionalDataFieldTemplate == nil || emvqr.AdditionalDataFieldTemplate.ReferenceLabel.Value == "" {
+		return nil, ErrReferenceNotPresent
+	}
+	return g.Lookup(emvqr.AdditionalDataFieldTemplate.ReferenceLabel.Value)
+}
+
+func (g *Generator) now() time.Time {
+	if g.Now != nil {
+		return g.Now()
+	}
+	return time.Now()
+}
+
+func (g *Generator) reference(reference string) (string, error) {
+	if reference != "" {
+		if utf8.RuneCountInString(reference) > maxReferenceLabelLength {
+			return "", fmt.Errorf("dynamic: Reference should be at most %d characters, Reference: %s", maxReferenceLabelLength, reference)
+		}
+		if _, err := g.Store.Load(reference); err == nil {
+			return "", ErrDuplicateReference
+		} else if err != ErrSessionNotFound {
+			return "", err
+		}
+		return reference, nil
+	}
+	for i := 0; i < maxReferenceAttempts; i++ {
+		reference, err := newReference()
+		if err != nil {
+			return "", err
+		}
+		_, err = g.Store.Load(reference)
+		if err == ErrSessionNotFound {
+			return reference, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return "", ErrDuplicateReference
+}
+
+// setExpiry writes the expiry into emvqr. An unreserved template of the
+// template is only written to when it has the GUI of the expiry tag.
+func (g *Generator) setExpiry(emvqr *mpm.EMVQR, expiresAt time.Time) error {
+	value := expiresAt.UTC().Format(g.Expiry.layout())
+	if g.Expiry.Template == mpm.IDAdditionalDataFieldTemplate {
+		additional := emvqr.AdditionalDataFieldTemplate
+		specific := make([]mpm.TLV, 0, len(additional.PaymentSystemSpecific))
+		for _, tlv := range additional.PaymentSystemSpecific {
+			if tlv.Tag != g.Expiry.ID {
+				specific = append(specific, tlv)
+			}
+		}
+		additional.PaymentSystemSpecific = specific
+		additional.AddPaymentSystemSpecific(g.Expiry.ID, value)
+		return nil
+	}
+	unreserved := new(mpm.UnreservedTemplate)
+	if t, ok := emvqr.UnreservedTemplates[g.Expiry.Template]; ok && t.Value != nil {
+		if gui := t.Value.GloballyUniqueIdentifier.Value; !strings.EqualFold(gui, g.Expiry.GloballyUniqueIdentifier) {
+			return fmt.Errorf("dynamic: template %s is in use by GloballyUniqueIdentifier %s", g.Expiry.Template, gui)
+		}
+		for _, tlv := range t.Value.ContextSpecificData {
+			if tlv.Tag != g.Expiry.ID {
+				unreserved.AddContextSpecificData(tlv.Tag, tlv.Value)
+			}
+		}
+	}
+	unreserved.SetGloballyUniqueIdentifier(g.Expiry.GloballyUniqueIdentifier)
+	unreserved.AddContextSpecificData(g.Expiry.ID, value)
+	emvqr.AddUnreservedTemplates(g.Expiry.Template, unreserved)
+	return nil
+}
+
+func newReference() (string, error) {
+	b := make([]byte, ReferenceLength/2)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return strings.ToUpper(hex.EncodeToString(b)), nil
+}
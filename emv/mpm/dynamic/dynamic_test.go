@@ -0,0 +1,257 @@
+package dynamic
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dongri/emv-qrcode/emv/mpm"
+)
+
+func newTemplate() *mpm.EMVQR {
+	return newTemplateWith(nil)
+}
+
+// newTemplateWith returns the template with the unreserved template 80 set to unreserved.
+func newTemplateWith(unreserved *mpm.UnreservedTemplate) *mpm.EMVQR {
+	emvqr := new(mpm.EMVQR)
+	emvqr.SetPayloadFormatIndicator("01")
+	emvqr.SetPointOfInitiationMethod(mpm.PointOfInitiationMethodStatic)
+	merchantAccountInformation := new(mpm.MerchantAccountInformation)
+	merchantAccountInformation.SetGloballyUniqueIdentifier("D15600000000")
+	merchantAccountInformation.AddPaymentNetworkSpecific("05", "A93FO3230Q")
+	emvqr.AddMerchantAccountInformation(mpm.ID("29"), merchantAccountInformation)
+	emvqr.SetMerchantCategoryCode("4111")
+	emvqr.SetTransactionCurrency("156")
+	emvqr.SetCountryCode("CN")
+	emvqr.SetMerchantName("BEST TRANSPORT")
+	emvqr.SetMerchantCity("BEIJING")
+	additional := new(mpm.AdditionalDataFieldTemplate)
+	additional.SetStoreLabel("1234")
+	emvqr.SetAdditionalDataFieldTemplate(additional)
+	if unreserved != nil {
+		emvqr.AddUnreservedTemplates("80", unreserved)
+	}
+	return emvqr
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name       string
+		expiry     *ExpiryTag
+		unreserved *mpm.UnreservedTemplate
+		ttl        time.Duration
+		req        Request
+		wantExpiry string
+		wantErr    bool
+	}{
+		{
+			name: "without expiry",
+			req: Request{
+				Amount:        "23.72",
+				TerminalLabel: "A6008667",
+			},
+		},
+		{
+			name: "expiry in payment system specific",
+			expiry: &ExpiryTag{
+				Template: mpm.IDAdditionalDataFieldTemplate,
+				ID:       "50",
+			},
+			ttl: time.Minute,
+			req: Request{
+				Amount: "1.00",
+			},
+			wantExpiry: "20200102030505",
+		},
+		{
+			name: "expiry in unreserved template",
+			expiry: &ExpiryTag{
+				Template:                 "80",
+				ID:                       "01",
+				GloballyUniqueIdentifier: "A011223344998877",
+			},
+			ttl: time.Minute,
+			req: Request{
+				Amount:    "1.00",
+				Reference: "INV-0001",
+			},
+			wantExpiry: "20200102030505",
+		},
+		{
+			name: "expiry in unreserved template of the template",
+			expiry: &ExpiryTag{
+				Template:                 "80",
+				ID:                       "01",
+				GloballyUniqueIdentifier: "A011223344998877",
+			},
+			unreserved: func() *mpm.UnreservedTemplate {
+				u := new(mpm.UnreservedTemplate)
+				u.SetGloballyUniqueIdentifier("a011223344998877")
+				u.AddContextSpecificData("02", "KEEP")
+				return u
+			}(),
+			ttl: time.Minute,
+			req: Request{
+				Amount: "1.00",
+			},
+			wantExpiry: "20200102030505",
+		},
+		{
+			name: "unreserved template of another GUI",
+			expiry: &ExpiryTag{
+				Template:                 "80",
+				ID:                       "01",
+				GloballyUniqueIdentifier: "A011223344998877",
+			},
+			unreserved: func() *mpm.UnreservedTemplate {
+				u := new(mpm.UnreservedTemplate)
+				u.SetGloballyUniqueIdentifier("com.example.other")
+				u.AddContextSpecificData("01", "OTHER")
+				return u
+			}(),
+			ttl: time.Minute,
+			req: Request{
+				Amount: "1.00",
+			},
+			wantErr: true,
+		},
+		{
+			name: "terminal label of 25 characters",
+			req: Request{
+				Amount:        "1.00",
+				TerminalLabel: strings.Repeat("A", 25),
+			},
+		},
+		{
+			name: "long terminal label",
+			req: Request{
+				Amount:        "1.00",
+				TerminalLabel: strings.Repeat("A", 26),
+			},
+			wantErr: true,
+		},
+		{
+			name: "lack of amount",
+			req: Request{
+				TerminalLabel: "A6008667",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid expiry tag",
+			expiry: &ExpiryTag{
+				Template: mpm.IDAdditionalDataFieldTemplate,
+				ID:       "10",
+			},
+			req: Request{
+				Amount: "1.00",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGenerator(newTemplateWith(tt.unreserved), NewMemoryStore())
+			g.Now = func() time.Time { return now }
+			g.TTL = tt.ttl
+			g.Expiry = tt.expiry
+			session, err := g.Generate(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Generator.Generate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			emvqr, err := mpm.Decode(session.Payload)
+			if err != nil {
+				t.Fatalf("mpm.Decode() error = %v", err)
+			}
+			if emvqr.PointOfInitiationMethod.Value != mpm.PointOfInitiationMethodDynamic {
+				t.Errorf("PointOfInitiationMethod = %v, want %v", emvqr.PointOfInitiationMethod.Value, mpm.PointOfInitiationMethodDynamic)
+			}
+			if emvqr.TransactionAmount.Value != tt.req.Amount {
+				t.Errorf("TransactionAmount = %v, want %v", emvqr.TransactionAmount.Value, tt.req.Amount)
+			}
+			if emvqr.AdditionalDataFieldTemplate.StoreLabel.Value != "1234" {
+				t.Errorf("StoreLabel = %v, want %v", emvqr.AdditionalDataFieldTemplate.StoreLabel.Value, "1234")
+			}
+			if got := emvqr.AdditionalDataFieldTemplate.ReferenceLabel.Value; got != session.Reference {
+				t.Errorf("ReferenceLabel = %v, want %v", got, session.Reference)
+			}
+			if tt.req.Reference != "" && session.Reference != tt.req.Reference {
+				t.Errorf("Session.Reference = %v, want %v", session.Reference, tt.req.Reference)
+			}
+			wantTerminalLabel := tt.req.TerminalLabel
+			if wantTerminalLabel == "" {
+				wantTerminalLabel = session.Reference
+			}
+			if got := emvqr.AdditionalDataFieldTemplate.TerminalLabel.Value; got != wantTerminalLabel || session.TerminalLabel != wantTerminalLabel {
+				t.Errorf("TerminalLabel = %v, Session.TerminalLabel = %v, want %v", got, session.TerminalLabel, wantTerminalLabel)
+			}
+			var gotExpiry string
+			if tt.expiry != nil && tt.expiry.Template == mpm.IDAdditionalDataFieldTemplate {
+				for _, tlv := range emvqr.AdditionalDataFieldTemplate.PaymentSystemSpecific {
+					if tlv.Tag == tt.expiry.ID {
+						gotExpiry = tlv.Value
+					}
+				}
+			}
+			if tt.expiry != nil && tt.expiry.Template != mpm.IDAdditionalDataFieldTemplate {
+				unreserved := emvqr.UnreservedTemplates[tt.expiry.Template].Value
+				if unreserved.GloballyUniqueIdentifier.Value != tt.expiry.GloballyUniqueIdentifier {
+					t.Errorf("GloballyUniqueIdentifier = %v, want %v", unreserved.GloballyUniqueIdentifier.Value, tt.expiry.GloballyUniqueIdentifier)
+				}
+				kept := tt.unreserved == nil
+				for _, tlv := range unreserved.ContextSpecificData {
+					if tlv.Tag == tt.expiry.ID {
+						gotExpiry = tlv.Value
+					}
+					if tlv.Tag == "02" && tlv.Value == "KEEP" {
+						kept = true
+					}
+				}
+				if !kept {
+					t.Errorf("ContextSpecificData = %v, want 02 of the template kept", unreserved.ContextSpecificData)
+				}
+			}
+			if gotExpiry != tt.wantExpiry {
+				t.Errorf("expiry = %v, want %v", gotExpiry, tt.wantExpiry)
+			}
+		})
+	}
+}
+
+func TestGenerator_LookupPayload(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	g := NewGenerator(newTemplate(), NewMemoryStore())
+	g.Now = func() time.Time { return now }
+	g.TTL = time.Minute
+	session, err := g.Generate(Request{Amount: "10"})
+	if err != nil {
+		t.Fatalf("Generator.Generate() error = %v", err)
+	}
+	if _, err := g.Generate(Request{Amount: "10", Reference: session.Reference}); err != ErrDuplicateReference {
+		t.Errorf("Generator.Generate() error = %v, want %v", err, ErrDuplicateReference)
+	}
+
+	got, err := g.LookupPayload(session.Payload)
+	if err != nil {
+		t.Fatalf("Generator.LookupPayload() error = %v", err)
+	}
+	if got.Reference != session.Reference || got.Payload != session.Payload {
+		t.Errorf("Generator.LookupPayload() = %v, want %v", got, session)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := g.Lookup(session.Reference); err != ErrSessionExpired {
+		t.Errorf("Generator.Lookup() error = %v, want %v", err, ErrSessionExpired)
+	}
+	if _, err := g.Lookup("UNKNOWN"); err != ErrSessionNotFound {
+		t.Errorf("Generator.Lookup() error = %v, want %v", err, ErrSessionNotFound)
+	}
+	if _, err := g.LookupPayload(newTemplate().GeneratePayload()); err != ErrReferenceNotPresent {
+		t.Errorf("Generator.LookupPayload() error = %v, want %v", err, ErrReferenceNotPresent)
+	}
+}
@@ -0,0 +1,59 @@
+package dynamic
+
+import (
+	"sync"
+)
+
+// Store keeps sessions by their Reference Label so that the payment callback
+// can be matched with the payload it was issued for.
+type Store interface {
+	Save(session *Session) error
+	Load(reference string) (*Session, error)
+	Delete(reference string) error
+}
+
+// MemoryStore ...
+type MemoryStore struct {
+	mu       sync.RWMutex
+	sessions map[string]Session
+}
+
+// NewMemoryStore ...
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: make(map[string]Session),
+	}
+}
+
+// Save ...
+func (s *MemoryStore) Save(session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sessions[session.Reference]; ok {
+		return ErrDuplicateReference
+	}
+	s.sessions[session.Reference] = *session
+	return nil
+}
+
+// Load ...
+func (s *MemoryStore) Load(reference string) (*Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	session, ok := s.sessions[reference]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+// Delete ...
+func (s *MemoryStore) Delete(reference string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sessions[reference]; !ok {
+		return ErrSessionNotFound
+	}
+	delete(s.sessions, reference)
+	return nil
+}
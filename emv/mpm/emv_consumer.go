@@ -0,0 +1,128 @@
+package mpm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConsumerDataRequest is the set of data the mobile application has to collect
+// from the consumer, as listed in the Additional Consumer Data Request (62.09).
+type ConsumerDataRequest uint8
+
+// Additional Consumer Data Request flags ...
+const (
+	ConsumerDataAddress ConsumerDataRequest = 1 << iota // "A"
+	ConsumerDataMobile                                  // "M"
+	ConsumerDataEmail                                   // "E"
+)
+
+var consumerDataRequestFlags = []struct {
+	flag   ConsumerDataRequest
+	letter rune
+	prompt string
+}{
+	{ConsumerDataAddress, 'A', "Address"},
+	{ConsumerDataMobile, 'M', "Mobile Number"},
+	{ConsumerDataEmail, 'E', "Email"},
+}
+
+// ParseConsumerDataRequest ...
+func ParseConsumerDataRequest(v string) (ConsumerDataRequest, error) {
+	var r ConsumerDataRequest
+	for _, c := range v {
+		flag, ok := consumerDataRequestFlag(c)
+		if !ok {
+			return 0, fmt.Errorf("AdditionalConsumerDataRequest should contain only \"A\", \"M\" or \"E\", AdditionalConsumerDataRequest: %s", v)
+		}
+		if r.Has(flag) {
+			return 0, fmt.Errorf("AdditionalConsumerDataRequest should not contain duplicates, AdditionalConsumerDataRequest: %s", v)
+		}
+		r |= flag
+	}
+	return r, nil
+}
+
+func consumerDataRequestFlag(c rune) (ConsumerDataRequest, bool) {
+	for _, f := range consumerDataRequestFlags {
+		if f.letter == c {
+			return f.flag, true
+		}
+	}
+	return 0, false
+}
+
+// Has ...
+func (r ConsumerDataRequest) Has(flag ConsumerDataRequest) bool {
+	return r&flag == flag
+}
+
+// String returns the 62.09 value, e.g. "AME".
+func (r ConsumerDataRequest) String() string {
+	var sb strings.Builder
+	for _, f := range consumerDataRequestFlags {
+		if r.Has(f.flag) {
+			sb.WriteRune(f.letter)
+		}
+	}
+	return sb.String()
+}
+
+// Prompts returns the prompts the mobile application should show, in "A", "M", "E" order.
+func (r ConsumerDataRequest) Prompts() []string {
+	var prompts []string
+	for _, f := range consumerDataRequestFlags {
+		if r.Has(f.flag) {
+			prompts = append(prompts, f.prompt)
+		}
+	}
+	return prompts
+}
+
+// ConsumerData is the data collected from the consumer for a ConsumerDataRequest.
+type ConsumerData struct {
+	Address string
+	Mobile  string
+	Email   string
+}
+
+// Check ...
+func (r ConsumerDataRequest) Check(d ConsumerData) error {
+	values := map[ConsumerDataRequest]string{
+		ConsumerDataAddress: d.Address,
+		ConsumerDataMobile:  d.Mobile,
+		ConsumerDataEmail:   d.Email,
+	}
+	for _, f := range consumerDataRequestFlags {
+		v := values[f.flag]
+		if r.Has(f.flag) && v == "" {
+			return fmt.Errorf("%s is requested by AdditionalConsumerDataRequest", f.prompt)
+		}
+		if !r.Has(f.flag) && v != "" {
+			return fmt.Errorf("%s is not requested by AdditionalConsumerDataRequest", f.prompt)
+		}
+	}
+	return nil
+}
+
+// SetConsumerDataRequest ...
+func (s *AdditionalDataFieldTemplate) SetConsumerDataRequest(r ConsumerDataRequest) {
+	s.SetAdditionalConsumerDataRequest(r.String())
+}
+
+// ConsumerDataRequest ...
+func (s *AdditionalDataFieldTemplate) ConsumerDataRequest() (ConsumerDataRequest, error) {
+	if s == nil {
+		return 0, nil
+	}
+	return ParseConsumerDataRequest(s.AdditionalConsumerDataRequest.Value)
+}
+
+// Validate ...
+func (s *AdditionalDataFieldTemplate) Validate() error {
+	if s.AdditionalConsumerDataRequest.Value != "" {
+		if _, err := ParseConsumerDataRequest(s.AdditionalConsumerDataRequest.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package mpm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseConsumerDataRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		want        ConsumerDataRequest
+		wantString  string
+		wantPrompts []string
+		wantErr     bool
+	}{
+		{
+			name:       "empty",
+			value:      "",
+			want:       0,
+			wantString: "",
+		},
+		{
+			name:        "mobile and email",
+			value:       "ME",
+			want:        ConsumerDataMobile | ConsumerDataEmail,
+			wantString:  "ME",
+			wantPrompts: []string{"Mobile Number", "Email"},
+		},
+		{
+			name:        "all in any order",
+			value:       "EAM",
+			want:        ConsumerDataAddress | ConsumerDataMobile | ConsumerDataEmail,
+			wantString:  "AME",
+			wantPrompts: []string{"Address", "Mobile Number", "Email"},
+		},
+		{
+			name:    "unknown letter",
+			value:   "AX",
+			wantErr: true,
+		},
+		{
+			name:    "lower case",
+			value:   "a",
+			wantErr: true,
+		},
+		{
+			name:    "duplicate",
+			value:   "MEM",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseConsumerDataRequest(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseConsumerDataRequest() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseConsumerDataRequest() = %v, want %v", got, tt.want)
+			}
+			if got.String() != tt.wantString {
+				t.Errorf("ConsumerDataRequest.String() = %v, want %v", got.String(), tt.wantString)
+			}
+			if !reflect.DeepEqual(got.Prompts(), tt.wantPrompts) {
+				t.Errorf("ConsumerDataRequest.Prompts() = %v, want %v", got.Prompts(), tt.wantPrompts)
+			}
+		})
+	}
+}
+
+func TestConsumerDataRequest_Check(t *testing.T) {
+	tests := []struct {
+		name    string
+		request ConsumerDataRequest
+		data    ConsumerData
+		wantErr bool
+	}{
+		{
+			name:    "ok",
+			request: ConsumerDataMobile | ConsumerDataEmail,
+			data: ConsumerData{
+				Mobile: "+8190000000",
+				Email:  "dongri@example.com",
+			},
+		},
+		{
+			name:    "missing requested",
+			request: ConsumerDataMobile | ConsumerDataEmail,
+			data: ConsumerData{
+				Mobile: "+8190000000",
+			},
+			wantErr: true,
+		},
+		{
+			name:    "not requested",
+			request: ConsumerDataEmail,
+			data: ConsumerData{
+				Address: "TOKYO",
+				Email:   "dongri@example.com",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.request.Check(tt.data); (err != nil) != tt.wantErr {
+				t.Errorf("ConsumerDataRequest.Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAdditionalDataFieldTemplate_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request string
+		wantErr bool
+	}{
+		{
+			name:    "ok",
+			request: "AME",
+		},
+		{
+			name:    "empty",
+			request: "",
+		},
+		{
+			name:    "invalid",
+			request: "AA",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &AdditionalDataFieldTemplate{}
+			s.SetAdditionalConsumerDataRequest(tt.request)
+			if err := s.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("AdditionalDataFieldTemplate.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package mpm
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// DecodeOptions ...
+// In strict mode DecodeWithOptions rejects duplicate IDs, a Payload Format
+// Indicator that is not the first data object, data after the CRC, a missing or
+// wrong CRC, unknown or non-numeric IDs, Globally Unique Identifiers that
+// fail ParseGUI, merchant PANs failing the Luhn check or the lengths of their
+// brand, context specific data the extension registered for the GUI of its
+// unreserved template does not accept and payloads that fail Validate.
+// In lenient mode the same problems are recorded as warnings on the result:
+// data objects with a non-numeric ID and data after the CRC are skipped, the
+// last of duplicate IDs wins as in ParseEMVQR.
+type DecodeOptions struct {
+	Strict       bool
+	LengthPolicy LengthPolicy
+}
+
+// DecodeResult ...
+// Warnings holds the problems found in lenient mode, in payload order followed
+// by the Validate and merchant PAN errors if any. Problems found while parsing are *ParserError.
+// In both modes the *NetworkError of NetworkWarnings come last.
+type DecodeResult struct {
+	EMVQR    *EMVQR
+	Warnings []error
+}
+
+// DecodeWithOptions ...
+func DecodeWithOptions(payload string, opts DecodeOptions) (*DecodeResult, error) {
+	policy := opts.LengthPolicy
+	if policy == LengthPolicyAuto {
+		policy = DetectLengthPolicy(payload)
+	}
+	state := &decodeState{strict: opts.Strict}
+	p := NewParserWithLengthPolicy(payload, policy)
+	p.decode = state
+	emvqr, err := parseEMVQR(p)
+	if err != nil {
+		return nil, err
+	}
+	if !state.crc {
+		e := missingCRCError("Decode")
+		e.Path = []ID{IDCRC}
+		e.Offset = int64(utf8.RuneCountInString(payload))
+		e.ByteOffset = int64(len(payload))
+		e.Payload = payload
+		if err := state.report(e); err != nil {
+			return nil, err
+		}
+	}
+	result := &DecodeResult{EMVQR: emvqr}
+	if err := emvqr.Validate(); err != nil {
+		if err := state.report(err); err != nil {
+			return result, err
+		}
+	}
+	if err := emvqr.validatePANs(); err != nil {
+		if err := state.report(err); err != nil {
+			return result, err
+		}
+	}
+	result.Warnings = state.warnings
+	for _, w := range emvqr.NetworkWarnings() {
+		result.Warnings = append(result.Warnings, w)
+	}
+	return result, nil
+}
+
+// decodeState is shared by a parser and the parsers of its templates.
+type decodeState struct {
+	strict   bool
+	crc      bool // the root CRC has been seen
+	warnings []error
+}
+
+// report returns err in strict mode and records it as a warning otherwise.
+func (d *decodeState) report(err error) error {
+	if d.strict {
+		return err
+	}
+	d.warnings = append(d.warnings, err)
+	return nil
+}
+
+type inspectResult int
+
+const (
+	inspectKeep inspectResult = iota // hand the data object to the caller
+	inspectSkip                      // move on to the next data object
+	inspectStop                      // stop parsing
+)
+
+// inspect checks the current data object against the rules of DecodeOptions.
+func (p *Parser) inspect() inspectResult {
+	const fnNext = "Next"
+	id := p.currentID()
+	if id == "" {
+		// truncated, reported by ID
+		return inspectKeep
+	}
+	root := len(p.path) == 0
+	if root && p.decode.crc {
+		if err := p.decode.report(p.locate(trailingDataError(fnNext), 0, 0)); err != nil {
+			p.err = err
+		}
+		return inspectStop
+	}
+	if !isNumericID(id) {
+		if err := p.decode.report(p.invalidIDError()); err != nil {
+			p.err = err
+			return inspectStop
+		}
+		return inspectSkip
+	}
+	var problems []*ParserError
+	if root && len(p.seen) == 0 && id != IDPayloadFormatIndicator {
+		problems = append(problems, orderError(fnNext, id))
+	}
+	if p.seen[id] {
+		problems = append(problems, duplicateIDError(fnNext, id))
+	}
+	if len(p.path) == 1 && p.path[0] == IDAdditionalDataFieldTemplate && id == "00" {
+		problems = append(problems, unknownIDError(fnNext, id))
+	}
+	if len(p.path) == 1 && id == MerchantAccountInformationIDGloballyUniqueIdentifier && isGUITemplate(p.path[0]) {
+		value := p.Value()
+		if p.err != nil {
+			return inspectKeep
+		}
+		if _, err := ParseGUI(value); err != nil {
+			problems = append(problems, &ParserError{Func: fnNext, Err: err})
+		}
+	}
+	if root && id == IDCRC {
+		p.decode.crc = true
+		value := p.Value()
+		if p.err != nil {
+			return inspectKeep
+		}
+		want := formatCrc(string(p.source[:p.current]))[IDWordCount+ValueLengthWordCount:]
+		if !strings.EqualFold(value, want) {
+			problems = append(problems, crcMismatchError(fnNext, value, want))
+		}
+	}
+	for _, e := range problems {
+		if err := p.decode.report(p.locate(e, 0, 0)); err != nil {
+			p.err = err
+			return inspectStop
+		}
+	}
+	if p.seen == nil {
+		p.seen = map[ID]bool{}
+	}
+	p.seen[id] = true
+	return inspectKeep
+}
+
+func isNumericID(id ID) bool {
+	return len(id) == IDWordCount && isDigit(id[0]) && isDigit(id[1])
+}
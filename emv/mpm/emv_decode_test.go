@@ -0,0 +1,149 @@
+package mpm
+
+import (
+	"reflect"
+	"testing"
+)
+
+const decodeBody = "000201010212021641111111111111115204411153031565802CN5914BEST TRANSPORT6007BEIJING"
+
+func withCRC(payload string) string {
+	return payload + formatCrc(payload)
+}
+
+func TestDecodeWithOptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		payload      string
+		wantPath     []ID // path of the strict error and the first warning
+		wantWarnings int
+		wantParseErr bool // an error in lenient mode as well
+	}{
+		{
+			name:    "ok",
+			payload: withCRC(decodeBody),
+		},
+		{
+			name:         "duplicate ID",
+			payload:      withCRC(decodeBody + "5902XY"),
+			wantPath:     []ID{"59"},
+			wantWarnings: 1,
+		},
+		{
+			name:         "duplicate nested ID",
+			payload:      withCRC(decodeBody + "62140503REF0503REF"),
+			wantPath:     []ID{"62", "05"},
+			wantWarnings: 1,
+		},
+		{
+			name:         "payload format indicator is not first",
+			payload:      withCRC(decodeBody[6:] + "000201"),
+			wantPath:     []ID{"01"},
+			wantWarnings: 1,
+		},
+		{
+			name:         "trailing data",
+			payload:      withCRC(decodeBody) + "5902XY",
+			wantPath:     []ID{"59"},
+			wantWarnings: 1,
+		},
+		{
+			name:         "missing CRC",
+			payload:      decodeBody,
+			wantPath:     []ID{"63"},
+			wantWarnings: 1,
+		},
+		{
+			name:         "CRC mismatch",
+			payload:      decodeBody + "6304FFFF",
+			wantPath:     []ID{"63"},
+			wantWarnings: 1,
+		},
+		{
+			name:         "unknown ID",
+			payload:      withCRC(decodeBody + "62060002XY"),
+			wantPath:     []ID{"62", "00"},
+			wantWarnings: 1,
+		},
+		{
+			name:         "not number id",
+			payload:      withCRC(decodeBody + "ab02XY"),
+			wantPath:     []ID{"ab"},
+			wantWarnings: 1,
+		},
+		{
+			name:         "not number nested id",
+			payload:      withCRC(decodeBody + "6206ab02XY"),
+			wantPath:     []ID{"62", "ab"},
+			wantWarnings: 1,
+		},
+		{
+			name:    "AID GUI",
+			payload: withCRC(decodeBody + "26180014A0000000031010"),
+		},
+		{
+			name:         "invalid GUI",
+			payload:      withCRC(decodeBody + "80080004abcd"),
+			wantPath:     []ID{"80", "00"},
+			wantWarnings: 2, // and the Validate error
+		},
+		{
+			name:         "invalid",
+			payload:      withCRC("000201"),
+			wantWarnings: 1,
+		},
+		{
+			name:         "several problems",
+			payload:      decodeBody[6:] + "5902XY6304FFFF",
+			wantPath:     []ID{"01"},
+			wantWarnings: 4,
+		},
+		{
+			name:         "broken payload",
+			payload:      decodeBody + "6310",
+			wantPath:     []ID{"63"},
+			wantParseErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeWithOptions(tt.payload, DecodeOptions{Strict: true})
+			if (err != nil) != (tt.wantWarnings > 0 || tt.wantParseErr) {
+				t.Fatalf("DecodeWithOptions(strict) error = %v", err)
+			}
+			if e, ok := err.(*ParserError); ok && !reflect.DeepEqual(e.Path, tt.wantPath) {
+				t.Errorf("DecodeWithOptions(strict) error path = %v, want %v", e.Path, tt.wantPath)
+			}
+
+			got, err := DecodeWithOptions(tt.payload, DecodeOptions{})
+			if (err != nil) != tt.wantParseErr {
+				t.Fatalf("DecodeWithOptions(lenient) error = %v, wantErr %v", err, tt.wantParseErr)
+			}
+			if tt.wantParseErr {
+				return
+			}
+			if len(got.Warnings) != tt.wantWarnings {
+				t.Fatalf("DecodeWithOptions(lenient) warnings = %v, want %d", got.Warnings, tt.wantWarnings)
+			}
+			if tt.wantWarnings == 0 {
+				return
+			}
+			if e, ok := got.Warnings[0].(*ParserError); ok && !reflect.DeepEqual(e.Path, tt.wantPath) {
+				t.Errorf("DecodeWithOptions(lenient) warning path = %v, want %v", e.Path, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestDecodeWithOptions_lenient(t *testing.T) {
+	got, err := DecodeWithOptions(withCRC(decodeBody+"ab02XY5902XY")+"5802JP", DecodeOptions{})
+	if err != nil {
+		t.Fatalf("DecodeWithOptions() error = %v", err)
+	}
+	if got.EMVQR.MerchantName.Value != "XY" {
+		t.Errorf("MerchantName = %v, want %v", got.EMVQR.MerchantName.Value, "XY")
+	}
+	if got.EMVQR.CountryCode.Value != "CN" {
+		t.Errorf("CountryCode = %v, want %v", got.EMVQR.CountryCode.Value, "CN")
+	}
+}
@@ -0,0 +1,163 @@
+package mpm
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/dongri/emv-qrcode/redact"
+)
+
+// ChangeKind ...
+type ChangeKind int
+
+// const ...
+const (
+	ChangeAdded ChangeKind = iota
+	ChangeRemoved
+	ChangeChanged
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeChanged:
+		return "changed"
+	}
+	return fmt.Sprintf("ChangeKind(%d)", int(k))
+}
+
+// Change is a data object that differs between two EMVQR.
+// Path is the ID path of the data object, e.g. "26.01" for ID 01 in template 26.
+type Change struct {
+	Kind ChangeKind
+	Path string
+	From string
+	To   string
+}
+
+// String returns c with the PANs of its values redacted by PrintRedactor.
+func (c Change) String() string {
+	return c.StringWith(PrintRedactor)
+}
+
+// StringWith is String with the PANs of the values redacted by r.
+func (c Change) StringWith(r redact.Redactor) string {
+	from, to := r.Text(c.From), r.Text(c.To)
+	if c.Path == IDCRC.String() {
+		return fmt.Sprintf("CRC differs: %q -> %q", from, to)
+	}
+	switch c.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("%s added: %q", c.Path, to)
+	case ChangeRemoved:
+		return fmt.Sprintf("%s removed: %q", c.Path, from)
+	}
+	return fmt.Sprintf("%s changed: %q -> %q", c.Path, from, to)
+}
+
+// Diff returns the data objects added, removed or changed from a to b, ordered by path.
+// The comparison is on the decoded data objects, so the order of the data objects
+// in the payloads does not matter.
+func Diff(a, b *EMVQR) []Change {
+	from := a.dataObjects()
+	to := b.dataObjects()
+	paths := make([]string, 0, len(from)+len(to))
+	for path := range from {
+		paths = append(paths, path)
+	}
+	for path := range to {
+		if _, ok := from[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	var changes []Change
+	for _, path := range paths {
+		f, inFrom := from[path]
+		t, inTo := to[path]
+		switch {
+		case !inFrom:
+			changes = append(changes, Change{Kind: ChangeAdded, Path: path, To: t})
+		case !inTo:
+			changes = append(changes, Change{Kind: ChangeRemoved, Path: path, From: f})
+		case f != t:
+			changes = append(changes, Change{Kind: ChangeChanged, Path: path, From: f, To: t})
+		}
+	}
+	return changes
+}
+
+// dataObjects flattens the primitive data objects of c by ID path.
+func (c *EMVQR) dataObjects() map[string]string {
+	m := map[string]string{}
+	if c == nil {
+		return m
+	}
+	add := func(prefix string, tlvs ...TLV) {
+		for _, tlv := range tlvs {
+			if tlv.Tag == "" {
+				continue
+			}
+			m[prefix+tlv.Tag.String()] = tlv.Value
+		}
+	}
+	add("",
+		c.PayloadFormatIndicator,
+		c.PointOfInitiationMethod,
+		c.MerchantCategoryCode,
+		c.TransactionCurrency,
+		c.TransactionAmount,
+		c.TipOrConvenienceIndicator,
+		c.ValueOfConvenienceFeeFixed,
+		c.ValueOfConvenienceFeePercentage,
+		c.CountryCode,
+		c.MerchantName,
+		c.MerchantCity,
+		c.PostalCode,
+		c.CRC,
+	)
+	add("", c.RFUforEMVCo...)
+	for id, t := range c.MerchantAccountInformation {
+		if t.Value == nil {
+			continue
+		}
+		if within, err := id.Between(IDMerchantAccountInformationPrimitiveRangeStart, IDMerchantAccountInformationPrimitiveRangeEnd); err == nil && within {
+			m[id.String()] = t.Value.Value
+			continue
+		}
+		add(id.String()+".", t.Value.GloballyUniqueIdentifier)
+		add(id.String()+".", t.Value.PaymentNetworkSpecific...)
+	}
+	if s := c.AdditionalDataFieldTemplate; s != nil {
+		prefix := IDAdditionalDataFieldTemplate.String() + "."
+		add(prefix,
+			s.BillNumber,
+			s.MobileNumber,
+			s.StoreLabel,
+			s.LoyaltyNumber,
+			s.ReferenceLabel,
+			s.CustomerLabel,
+			s.TerminalLabel,
+			s.PurposeTransaction,
+			s.AdditionalConsumerDataRequest,
+		)
+		add(prefix, s.RFUforEMVCo...)
+		add(prefix, s.PaymentSystemSpecific...)
+	}
+	if s := c.MerchantInformationLanguageTemplate; s != nil {
+		prefix := IDMerchantInformationLanguageTemplate.String() + "."
+		add(prefix, s.LanguagePreference, s.MerchantName, s.MerchantCity)
+		add(prefix, s.RFUforEMVCo...)
+	}
+	for id, t := range c.UnreservedTemplates {
+		if t.Value == nil {
+			continue
+		}
+		add(id.String()+".", t.Value.GloballyUniqueIdentifier)
+		add(id.String()+".", t.Value.ContextSpecificData...)
+	}
+	return m
+}
@@ -0,0 +1,69 @@
+package mpm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want []string
+	}{
+		{
+			name: "same",
+			a:    withCRC(decodeBody),
+			b:    withCRC(decodeBody),
+			want: nil,
+		},
+		{
+			name: "reordered",
+			a:    withCRC(decodeBody + "62090505REF01"),
+			b:    withCRC("62090505REF01" + decodeBody),
+			want: []string{`CRC differs: "` + formatCrc(decodeBody + "62090505REF01")[4:] + `" -> "` + formatCrc("62090505REF01" + decodeBody)[4:] + `"`},
+		},
+		{
+			name: "added, removed and changed",
+			a:    decodeBody + "26200010D1234567890102AB",
+			b:    decodeBody[:len(decodeBody)-11] + "6005TOKYO" + "26200010D1234567890102CD" + "62090505REF01",
+			want: []string{
+				`26.01 changed: "AB" -> "CD"`,
+				`60 changed: "BEIJING" -> "TOKYO"`,
+				`62.05 added: "REF01"`,
+			},
+		},
+		{
+			name: "removed",
+			a:    decodeBody + "64060002ZH",
+			b:    decodeBody,
+			want: []string{`64.00 removed: "ZH"`},
+		},
+		{
+			name: "PAN redacted",
+			a:    decodeBody + "02164111111111111111",
+			b:    decodeBody + "02165555555555554444",
+			want: []string{`02 changed: "411111******1111" -> "555555******4444"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := ParseEMVQR(tt.a)
+			if err != nil {
+				t.Fatalf("ParseEMVQR() error = %v", err)
+			}
+			b, err := ParseEMVQR(tt.b)
+			if err != nil {
+				t.Fatalf("ParseEMVQR() error = %v", err)
+			}
+			var got []string
+			for _, c := range Diff(a, b) {
+				got = append(got, c.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package mpm
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dongri/emv-qrcode/redact"
+)
+
+// DumpOptions ...
+type DumpOptions struct {
+	Color  bool             // ANSI colours, for terminals
+	Redact *redact.Redactor // redacts the PANs found in values, redact.Default if nil
+}
+
+// redactor returns the redactor of o, &redact.Redactor{} printing values as is.
+func (o DumpOptions) redactor() redact.Redactor {
+	if o.Redact == nil {
+		return redact.Default
+	}
+	return *o.Redact
+}
+
+// ANSI colours used by Dump.
+const (
+	colorReset   = "\x1b[0m"
+	colorID      = "\x1b[36m" // cyan
+	colorValue   = "\x1b[32m" // green
+	colorNetwork = "\x1b[33m" // yellow
+)
+
+// Dump writes the data objects of payload to w as an annotated tree, in payload order:
+//
+//	62 09 Additional Data Field Template
+//	  05 05 Reference Label: "REF01"
+//
+// Known payment networks are shown after primitive Merchant Account Information
+// and Globally Unique Identifiers. If the payload is broken, the data objects
+// before the error are written and the parser error is returned.
+func Dump(w io.Writer, payload string, opts DumpOptions) error {
+	d := &dumper{w: w, opts: opts}
+	return d.dump(NewParserWithLengthPolicy(payload, DetectLengthPolicy(payload)))
+}
+
+type dumper struct {
+	w    io.Writer
+	opts DumpOptions
+	err  error
+}
+
+func (d *dumper) dump(p *Parser) error {
+	var template ID
+	if len(p.path) > 0 {
+		template = p.path[len(p.path)-1]
+	}
+	for p.Next() {
+		id := p.ID()
+		length := p.ValueLength()
+		value := p.Value()
+		if p.Err() != nil {
+			break
+		}
+		var sb strings.Builder
+		sb.WriteString(strings.Repeat("  ", len(p.path)))
+		sb.WriteString(d.paint(colorID, fmt.Sprintf("%s %02d", id, length)))
+		if name := IDName(template, id); name != "" {
+			sb.WriteString(" " + name)
+		}
+		if template == "" && isTemplate(id) {
+			d.println(sb.String())
+			if err := d.dump(p.child(value)); err != nil {
+				return err
+			}
+			continue
+		}
+		sb.WriteString(": " + d.paint(colorValue, fmt.Sprintf("%q", d.opts.redactor().Text(value))))
+		if network := dumpNetwork(template, id, value); network != "" {
+			sb.WriteString(" " + d.paint(colorNetwork, "("+network+")"))
+		}
+		d.println(sb.String())
+	}
+	if err := p.Err(); err != nil {
+		return err
+	}
+	return d.err
+}
+
+// dumpNetwork returns the payment network of a data object, if any.
+func dumpNetwork(template, id ID, value string) string {
+	if template == "" {
+		return lookupName(primitiveNetworks, id)
+	}
+	// 00 is the Globally Unique Identifier in templates 26-51 and 80-99
+	if id != MerchantAccountInformationIDGloballyUniqueIdentifier || template == IDAdditionalDataFieldTemplate || template == IDMerchantInformationLanguageTemplate {
+		return ""
+	}
+	return NetworkName(value)
+}
+
+func (d *dumper) paint(color, s string) string {
+	if !d.opts.Color {
+		return s
+	}
+	return color + s + colorReset
+}
+
+func (d *dumper) println(s string) {
+	if d.err != nil {
+		return
+	}
+	_, d.err = fmt.Fprintln(d.w, s)
+}
@@ -0,0 +1,95 @@
+package mpm
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dongri/emv-qrcode/redact"
+)
+
+func TestDump(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		opts    DumpOptions
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "ok",
+			payload: "000201021641111111111111112618" + "0014A0000000031010" + "62090505REF01" + "6304ABCD",
+			want: "00 02 Payload Format Indicator: \"01\"\n" +
+				"02 16 Merchant Account Information: \"411111******1111\" (Visa)\n" +
+				"26 18 Merchant Account Information\n" +
+				"  00 14 Globally Unique Identifier: \"A0000000031010\" (Visa)\n" +
+				"62 09 Additional Data Field Template\n" +
+				"  05 05 Reference Label: \"REF01\"\n" +
+				"63 04 CRC: \"ABCD\"\n",
+		},
+		{
+			name:    "mask",
+			payload: "000201021641111111111111116304ABCD",
+			opts:    DumpOptions{Redact: &redact.Redactor{Policy: redact.Mask}},
+			want: "00 02 Payload Format Indicator: \"01\"\n" +
+				"02 16 Merchant Account Information: \"****************\" (Visa)\n" +
+				"63 04 CRC: \"ABCD\"\n",
+		},
+		{
+			name:    "without redaction",
+			payload: "000201021641111111111111116304ABCD",
+			opts:    DumpOptions{Redact: &redact.Redactor{}},
+			want: "00 02 Payload Format Indicator: \"01\"\n" +
+				"02 16 Merchant Account Information: \"4111111111111111\" (Visa)\n" +
+				"63 04 CRC: \"ABCD\"\n",
+		},
+		{
+			name:    "color",
+			payload: "000201",
+			opts:    DumpOptions{Color: true},
+			want:    "\x1b[36m00 02\x1b[0m Payload Format Indicator: \x1b[32m\"01\"\x1b[0m\n",
+		},
+		{
+			name:    "broken",
+			payload: "00020162060599",
+			want:    "00 02 Payload Format Indicator: \"01\"\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Dump(&buf, tt.payload, tt.opts); (err != nil) != tt.wantErr {
+				t.Errorf("Dump() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Dump() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIDName(t *testing.T) {
+	tests := []struct {
+		template ID
+		id       ID
+		want     string
+	}{
+		{"", "00", "Payload Format Indicator"},
+		{"", "30", "Merchant Account Information"},
+		{"", "70", "RFU for EMVCo"},
+		{"62", "05", "Reference Label"},
+		{"62", "00", ""},
+		{"62", "55", "Payment System specific templates"},
+		{"64", "01", "Merchant Name—Alternate Language"},
+		{"26", "00", "Globally Unique Identifier"},
+		{"85", "01", "Context Specific Data"},
+		{"", "ab", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template.String()+"."+tt.id.String(), func(t *testing.T) {
+			if got := IDName(tt.template, tt.id); got != tt.want {
+				t.Errorf("IDName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
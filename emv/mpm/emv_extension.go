@@ -0,0 +1,191 @@
+package mpm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// extensions maps the GUIs of registered unreserved templates to their Go
+// types and back.
+var extensions = struct {
+	sync.RWMutex
+	types map[GUI]reflect.Type
+	guis  map[reflect.Type]GUI
+}{
+	types: map[GUI]reflect.Type{},
+	guis:  map[reflect.Type]GUI{},
+}
+
+// RegisterExtension registers the struct type of v, or of what v points to,
+// as the extension of the unreserved templates (80-99) with GUI gui.
+// Its fields are tagged as described by Marshal, with the IDs of context
+// specific data objects (01-99). Decoding sets the Extension of such
+// templates, and SetExtension encodes one. As with gob.Register, it is meant
+// to be called from init and panics on a type or GUI registered twice.
+func RegisterExtension(gui string, v interface{}) {
+	g, err := ParseGUI(gui)
+	if err != nil {
+		panic("mpm: RegisterExtension: " + err.Error())
+	}
+	typ := reflect.TypeOf(v)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("mpm: RegisterExtension: %T should be a struct or a pointer to a struct", v))
+	}
+	if err := checkExtension(typ); err != nil {
+		panic("mpm: RegisterExtension: " + err.Error())
+	}
+	extensions.Lock()
+	defer extensions.Unlock()
+	if t, ok := extensions.types[g]; ok {
+		panic(fmt.Sprintf("mpm: RegisterExtension: GUI %s is registered for %s", g, t))
+	}
+	if r, ok := extensions.guis[typ]; ok {
+		panic(fmt.Sprintf("mpm: RegisterExtension: %s is registered for GUI %s", typ, r))
+	}
+	extensions.types[g] = typ
+	extensions.guis[typ] = g
+}
+
+func checkExtension(typ reflect.Type) error {
+	fields, err := typeFields(typ)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if f.start < UnreservedTemplateIDContextSpecificDataStart || f.crc {
+			return fmt.Errorf("%s.%s should be context specific data (01-99), ID: %s", typ, f.name, f.start)
+		}
+	}
+	return nil
+}
+
+// extensionType returns the registered type of gui, if any.
+func extensionType(gui string) (reflect.Type, bool) {
+	g, err := ParseGUI(gui)
+	if err != nil {
+		return nil, false
+	}
+	extensions.RLock()
+	defer extensions.RUnlock()
+	typ, ok := extensions.types[g]
+	return typ, ok
+}
+
+// SetExtension sets the GUI registered for the type of v and marshals v as
+// the context specific data of the template, replacing it. v is kept as the
+// Extension of the template, and encoding the template marshals it again, so
+// later changes to v are encoded too.
+func (s *UnreservedTemplate) SetExtension(v interface{}) error {
+	rv, gui, err := extensionValue(v)
+	if err != nil {
+		return err
+	}
+	data, err := marshalTemplate(rv, false)
+	if err != nil {
+		return err
+	}
+	t, err := ParseUnreservedTemplate(data)
+	if err != nil {
+		return err
+	}
+	s.SetGUI(gui)
+	s.ContextSpecificData = t.ContextSpecificData
+	s.Extension = v
+	return nil
+}
+
+// MarshalEMV returns the data objects of the template, with its Extension, if
+// any, marshaled in place of ContextSpecificData.
+func (s *UnreservedTemplate) MarshalEMV() (string, error) {
+	if s.Extension == nil {
+		return marshalTemplate(reflect.ValueOf(s).Elem(), false)
+	}
+	rv, _, err := extensionValue(s.Extension)
+	if err != nil {
+		return "", err
+	}
+	data, err := marshalTemplate(rv, false)
+	if err != nil {
+		return "", err
+	}
+	if gui := s.GloballyUniqueIdentifier.Value; gui != "" {
+		data = format(UnreservedTemplateIDGloballyUniqueIdentifier, gui) + data
+	}
+	return data, nil
+}
+
+// synced returns a copy of the template with its Extension, if any, marshaled
+// in place of ContextSpecificData, as MarshalEMV encodes it. The printers and
+// Redact use it, so that they show later changes to the Extension too.
+func (s *UnreservedTemplate) synced() (*UnreservedTemplate, error) {
+	if s.Extension == nil {
+		return s, nil
+	}
+	rv, _, err := extensionValue(s.Extension)
+	if err != nil {
+		return nil, err
+	}
+	data, err := marshalTemplate(rv, false)
+	if err != nil {
+		return nil, err
+	}
+	t, err := ParseUnreservedTemplate(data)
+	if err != nil {
+		return nil, err
+	}
+	d := *s
+	d.ContextSpecificData = t.ContextSpecificData
+	return &d, nil
+}
+
+// extensionValue returns the struct of v, a registered extension, and its GUI.
+func extensionValue(v interface{}) (reflect.Value, GUI, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, "", fmt.Errorf("mpm: extension should be a registered struct, extension: %T", v)
+	}
+	extensions.RLock()
+	gui, ok := extensions.guis[rv.Type()]
+	extensions.RUnlock()
+	if !ok {
+		return reflect.Value{}, "", fmt.Errorf("mpm: extension should be a registered struct, extension: %T", v)
+	}
+	return rv, gui, nil
+}
+
+// fnExtension is the Func of the errors of extensions that do not decode.
+const fnExtension = "Extension"
+
+// decoded implements decodeHook.
+func (s *UnreservedTemplate) decoded() error {
+	return s.decodeExtension()
+}
+
+// decodeExtension sets the Extension of the template to a new value of the
+// type registered for its GUI, if any, decoded from its context specific data.
+// Data the type does not accept, such as values over max, leaves it nil and is
+// returned as an error; a GUI of no registered type is not.
+func (s *UnreservedTemplate) decodeExtension() error {
+	typ, ok := extensionType(s.GloballyUniqueIdentifier.Value)
+	if !ok {
+		return nil
+	}
+	var data strings.Builder
+	for _, tlv := range s.ContextSpecificData {
+		data.WriteString(tlv.String())
+	}
+	v := reflect.New(typ)
+	if err := unmarshalTemplate(NewParser(data.String()), v.Elem()); err != nil {
+		return fmt.Errorf("extension %s: %w", typ, err)
+	}
+	s.Extension = v.Interface()
+	return nil
+}
@@ -0,0 +1,177 @@
+package mpm
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testLoyalty struct {
+	Program string `emv:"01"`
+	Points  string `emv:"02"`
+	Expiry  string `emv:"05,max=4"`
+	Note    string
+}
+
+func init() {
+	RegisterExtension("com.example.loyalty", &testLoyalty{})
+}
+
+func TestUnreservedTemplate_SetExtension(t *testing.T) {
+	u := &UnreservedTemplate{}
+	if err := u.SetExtension(&testLoyalty{Program: "GOLD", Expiry: "2612", Note: "ignored"}); err != nil {
+		t.Fatalf("UnreservedTemplate.SetExtension() error = %v", err)
+	}
+	if want := "0019com.example.loyalty0104GOLD05042612"; u.String() != want {
+		t.Errorf("UnreservedTemplate.String() = %v, want %v", u.String(), want)
+	}
+	if err := u.SetExtension(struct{ Program string }{}); err == nil {
+		t.Errorf("UnreservedTemplate.SetExtension() of an unregistered struct should fail")
+	}
+}
+
+func TestUnreservedTemplate_MarshalEMV(t *testing.T) {
+	c := &EMVQR{}
+	c.SetPayloadFormatIndicator("01")
+	loyalty := &testLoyalty{Program: "GOLD", Points: "1200"}
+	u := &UnreservedTemplate{}
+	if err := u.SetExtension(loyalty); err != nil {
+		t.Fatalf("UnreservedTemplate.SetExtension() error = %v", err)
+	}
+	c.AddUnreservedTemplates("81", u)
+
+	loyalty.Points = "35000"
+	want := withCRC("000201" + "81400019com.example.loyalty0104GOLD020535000")
+	if got := c.GeneratePayload(); got != want {
+		t.Errorf("EMVQR.GeneratePayload() = %v, want %v", got, want)
+	}
+	parsed, err := ParseEMVQR(want)
+	if err != nil {
+		t.Fatalf("ParseEMVQR() error = %v", err)
+	}
+	if got := parsed.UnreservedTemplates["81"].Value.Extension; !reflect.DeepEqual(got, loyalty) {
+		t.Errorf("ParseEMVQR() extension = %+v, want %+v", got, loyalty)
+	}
+
+	u.Extension = &struct{ Program string }{}
+	if _, err := u.MarshalEMV(); err == nil {
+		t.Errorf("UnreservedTemplate.MarshalEMV() of an unregistered extension should fail")
+	}
+}
+
+func TestUnreservedTemplate_printExtension(t *testing.T) {
+	c := &EMVQR{}
+	c.SetPayloadFormatIndicator("01")
+	loyalty := &testLoyalty{Program: "GOLD", Points: "1200"}
+	u := &UnreservedTemplate{}
+	if err := u.SetExtension(loyalty); err != nil {
+		t.Fatalf("UnreservedTemplate.SetExtension() error = %v", err)
+	}
+	c.AddUnreservedTemplates("81", u)
+
+	loyalty.Program = "SILV"
+	loyalty.Points = "35000"
+	if got, want := c.RawData(), "00 02 01\n81 40\n  00 19 com.example.loyalty\n  01 04 SILV\n  02 05 35000\n"; got != want {
+		t.Errorf("EMVQR.RawData() = %q, want %q", got, want)
+	}
+	got := c.JSON()
+	if !strings.Contains(got, `"Value":"SILV"`) || !strings.Contains(got, `"Program":"SILV"`) || strings.Contains(got, "GOLD") {
+		t.Errorf("EMVQR.JSON() = %v, want SILV in place of GOLD", got)
+	}
+	if u.ContextSpecificData[0].Value != "GOLD" || u.Extension != loyalty {
+		t.Errorf("EMVQR.JSON() changed the template: %+v", u)
+	}
+}
+
+func TestUnreservedTemplate_decodeExtension(t *testing.T) {
+	c := &EMVQR{}
+	c.SetPayloadFormatIndicator("01")
+	u := &UnreservedTemplate{}
+	if err := u.SetExtension(testLoyalty{Program: "GOLD", Points: "1200"}); err != nil {
+		t.Fatalf("UnreservedTemplate.SetExtension() error = %v", err)
+	}
+	c.AddUnreservedTemplates("81", u)
+	other := &UnreservedTemplate{}
+	other.SetGloballyUniqueIdentifier("com.example.invoice")
+	other.AddContextSpecificData("01", "INV-1")
+	c.AddUnreservedTemplates("82", other)
+	payload := c.GeneratePayload()
+
+	want := &testLoyalty{Program: "GOLD", Points: "1200"}
+	parsed, err := ParseEMVQR(payload)
+	if err != nil {
+		t.Fatalf("ParseEMVQR() error = %v", err)
+	}
+	if got := parsed.UnreservedTemplates["81"].Value.Extension; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseEMVQR() extension = %+v, want %+v", got, want)
+	}
+	if got := parsed.UnreservedTemplates["82"].Value.Extension; got != nil {
+		t.Errorf("ParseEMVQR() extension of an unregistered GUI = %+v, want nil", got)
+	}
+	scanned, err := ParseEMVQRBytes([]byte(payload))
+	if err != nil {
+		t.Fatalf("ParseEMVQRBytes() error = %v", err)
+	}
+	if got := scanned.UnreservedTemplates["81"].Value.Extension; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseEMVQRBytes() extension = %+v, want %+v", got, want)
+	}
+}
+
+func TestUnreservedTemplate_decodeExtension_error(t *testing.T) {
+	payload := withCRC("000201" + "81320019com.example.loyalty050526121")
+	if _, err := ParseEMVQR(payload); err == nil {
+		t.Errorf("ParseEMVQR() error = nil, want the extension error")
+	}
+	if _, err := ParseEMVQRBytes([]byte(payload)); err == nil {
+		t.Errorf("ParseEMVQRBytes() error = nil, want the extension error")
+	}
+	_, err := DecodeWithOptions(payload, DecodeOptions{Strict: true})
+	var pe *ParserError
+	if !errors.As(err, &pe) || !reflect.DeepEqual(pe.Path, []ID{"81"}) || pe.Offset != 10 {
+		t.Errorf("DecodeWithOptions(strict) error = %v, want a *ParserError at 81, offset 10", err)
+	}
+	result, err := DecodeWithOptions(payload, DecodeOptions{})
+	if err != nil {
+		t.Fatalf("DecodeWithOptions() error = %v", err)
+	}
+	if len(result.Warnings) == 0 || !errors.As(result.Warnings[0], &pe) || pe.Func != fnExtension {
+		t.Errorf("DecodeWithOptions() warnings = %v, want the extension error", result.Warnings)
+	}
+	if got := result.EMVQR.UnreservedTemplates["81"].Value.Extension; got != nil {
+		t.Errorf("DecodeWithOptions() extension = %+v, want nil", got)
+	}
+}
+
+func TestRegisterExtension_panics(t *testing.T) {
+	tests := []struct {
+		name string
+		gui  string
+		v    interface{}
+	}{
+		{name: "invalid GUI", gui: "abcd", v: &struct{}{}},
+		{name: "not a struct", gui: "com.example.string", v: ""},
+		{name: "registered GUI", gui: "COM.EXAMPLE.LOYALTY", v: &struct{}{}},
+		{name: "registered type", gui: "com.example.other", v: testLoyalty{}},
+		{name: "invalid ID", gui: "com.example.id", v: &struct {
+			GUI string `emv:"00"`
+		}{}},
+		{name: "not a string", gui: "com.example.int", v: &struct {
+			Points int `emv:"01"`
+		}{}},
+		{name: "same ID", gui: "com.example.same", v: &struct {
+			A string `emv:"01"`
+			B string `emv:"01"`
+		}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterExtension() should panic")
+				}
+			}()
+			RegisterExtension(tt.gui, tt.v)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package mpm
+
+import (
+	"testing"
+)
+
+var fuzzSeeds = []string{
+	"",
+	benchmarkPayload,
+	decodeBody,
+	"000201" + languageTemplateBytes + "6304ABCD",
+	"00-1",
+	"00+1A",
+	"0099",
+	"62060599",
+	"6206ab02XY",
+	"6403ZH0104最佳",
+}
+
+func addSeeds(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add(s)
+	}
+}
+
+// FuzzParseEMVQR checks that parsing never panics and that the string and
+// []byte parsers agree.
+func FuzzParseEMVQR(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, payload string) {
+		_, err := ParseEMVQR(payload)
+		_, errBytes := ParseEMVQRBytes([]byte(payload))
+		if (err != nil) != (errBytes != nil) {
+			t.Fatalf("ParseEMVQR() error = %v, ParseEMVQRBytes() error = %v", err, errBytes)
+		}
+		for _, policy := range []LengthPolicy{LengthPolicyRunes, LengthPolicyBytes} {
+			ParseEMVQRWithLengthPolicy(payload, policy)
+		}
+		Decode(payload)
+	})
+}
+
+func FuzzDecodeWithOptions(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, payload string) {
+		for _, strict := range []bool{true, false} {
+			if result, err := DecodeWithOptions(payload, DecodeOptions{Strict: strict}); err == nil && result.EMVQR == nil {
+				t.Fatalf("DecodeWithOptions() = %v, want EMVQR", result)
+			}
+		}
+	})
+}
+
+func FuzzParseAdditionalDataFieldTemplate(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, value string) {
+		ParseAdditionalDataFieldTemplate(value)
+	})
+}
+
+func FuzzParseMerchantAccountInformation(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, value string) {
+		ParseMerchantAccountInformation(value)
+	})
+}
+
+func FuzzParseMerchantInformationLanguageTemplate(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, value string) {
+		ParseMerchantInformationLanguageTemplate(value)
+	})
+}
+
+func FuzzParseUnreservedTemplate(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, value string) {
+		ParseUnreservedTemplate(value)
+	})
+}
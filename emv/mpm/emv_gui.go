@@ -0,0 +1,224 @@
+package mpm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// GUIKind is the form of a Globally Unique Identifier.
+type GUIKind int
+
+// const ...
+const (
+	GUIUnknown       GUIKind = iota
+	GUIAID                   // RID and PIX, 5 to 16 bytes in hex, such as "A0000000031010"
+	GUIUUID                  // UUID in hex without hyphens
+	GUIReverseDomain         // reverse domain name, such as "com.example.loyalty"
+)
+
+// String ...
+func (k GUIKind) String() string {
+	switch k {
+	case GUIAID:
+		return "AID"
+	case GUIUUID:
+		return "UUID"
+	case GUIReverseDomain:
+		return "reverse domain name"
+	}
+	return "unknown"
+}
+
+// GUIMaxLength is the maximum length of a Globally Unique Identifier.
+const GUIMaxLength = 32
+
+// GUI is a Globally Unique Identifier, subtag 00 of the templates 26-51 and
+// 80-99, in its normal form: AIDs and UUIDs in upper case, reverse domain
+// names in lower case.
+type GUI string
+
+// ParseGUI classifies, validates and normalises a Globally Unique Identifier.
+// 32 hex digits are a UUID when they hold an RFC 4122 version and variant, and
+// an AID otherwise.
+func ParseGUI(v string) (GUI, error) {
+	switch kind := classifyGUI(v); kind {
+	case GUIAID, GUIUUID:
+		return GUI(strings.ToUpper(v)), nil
+	case GUIReverseDomain:
+		return GUI(strings.ToLower(v)), nil
+	}
+	return "", fmt.Errorf("GloballyUniqueIdentifier should be an AID, a UUID without hyphens or a reverse domain name, GloballyUniqueIdentifier: %s", v)
+}
+
+// Kind ...
+func (g GUI) Kind() GUIKind {
+	return classifyGUI(string(g))
+}
+
+// Network returns the payment network of the GUI, see NetworkName.
+func (g GUI) Network() string {
+	return NetworkName(string(g))
+}
+
+// String ...
+func (g GUI) String() string {
+	return string(g)
+}
+
+func classifyGUI(v string) GUIKind {
+	if len(v) > GUIMaxLength {
+		return GUIUnknown
+	}
+	if isHex(v) {
+		if len(v) == 32 && isUUID(v) {
+			return GUIUUID
+		}
+		// registered RIDs start with A (international) or D (national)
+		if len(v) >= 10 && len(v)%2 == 0 && strings.ContainsRune("AaDd", rune(v[0])) {
+			return GUIAID
+		}
+	}
+	if isReverseDomain(v) {
+		return GUIReverseDomain
+	}
+	return GUIUnknown
+}
+
+func isHex(v string) bool {
+	if v == "" {
+		return false
+	}
+	for i := 0; i < len(v); i++ {
+		if !isDigit(v[i]) && !strings.ContainsRune("ABCDEFabcdef", rune(v[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// isUUID reports whether the 32 hex digits of v hold an RFC 4122 version (1 to
+// 8) and variant.
+func isUUID(v string) bool {
+	return strings.ContainsRune("12345678", rune(v[12])) && strings.ContainsRune("89ABab", rune(v[16]))
+}
+
+// isReverseDomain reports whether v is at least two dot separated labels of
+// letters, digits and inner hyphens, the first (the top level domain) letters
+// only.
+func isReverseDomain(v string) bool {
+	labels := strings.Split(v, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for i, label := range labels {
+		if label == "" || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for j := 0; j < len(label); j++ {
+			c := label[j]
+			letter := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+			if !letter && (i == 0 || !isDigit(c) && c != '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// NewUUIDGUI returns a random (version 4) UUID GUI read from r, such as
+// crypto/rand.Reader, for private unreserved templates (80-99).
+func NewUUIDGUI(r io.Reader) (GUI, error) {
+	var b [16]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0F | 0x40
+	b[8] = b[8]&0x3F | 0x80
+	return GUI(fmt.Sprintf("%X", b)), nil
+}
+
+// NewReverseDomainGUI returns the reverse domain name GUI of a domain the
+// caller owns and names under it, such as "com.example.loyalty" for
+// "example.com" and "loyalty", for private unreserved templates (80-99).
+func NewReverseDomainGUI(domain string, names ...string) (GUI, error) {
+	if domain == "" {
+		return "", errors.New("domain is mandatory")
+	}
+	labels := strings.Split(domain, ".")
+	for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+		labels[i], labels[j] = labels[j], labels[i]
+	}
+	g, err := ParseGUI(strings.Join(append(labels, names...), "."))
+	if err != nil || g.Kind() != GUIReverseDomain {
+		return "", fmt.Errorf("GloballyUniqueIdentifier should be a reverse domain name of up to %d characters, domain: %s", GUIMaxLength, domain)
+	}
+	return g, nil
+}
+
+// GUI returns the Globally Unique Identifier of the template, parsed by ParseGUI.
+func (s *MerchantAccountInformation) GUI() (GUI, error) {
+	return ParseGUI(s.GloballyUniqueIdentifier.Value)
+}
+
+// SetGUI ...
+func (s *MerchantAccountInformation) SetGUI(g GUI) {
+	s.SetGloballyUniqueIdentifier(g.String())
+}
+
+// GUI returns the Globally Unique Identifier of the template, parsed by ParseGUI.
+func (s *UnreservedTemplate) GUI() (GUI, error) {
+	return ParseGUI(s.GloballyUniqueIdentifier.Value)
+}
+
+// SetGUI ...
+func (s *UnreservedTemplate) SetGUI(g GUI) {
+	s.SetGloballyUniqueIdentifier(g.String())
+}
+
+// validateGUIs checks subtag 00 of the Merchant Account Information templates
+// (26-51) and the Unreserved Templates (80-99) with ParseGUI.
+func (c *EMVQR) validateGUIs() error {
+	ids := make([]ID, 0, len(c.MerchantAccountInformation)+len(c.UnreservedTemplates))
+	guis := map[ID]string{}
+	for id, m := range c.MerchantAccountInformation {
+		if !isGUITemplate(id) || m.Value == nil || m.Value.Value != "" {
+			continue
+		}
+		ids = append(ids, id)
+		guis[id] = m.Value.GloballyUniqueIdentifier.Value
+	}
+	for id, t := range c.UnreservedTemplates {
+		if !isGUITemplate(id) || t.Value == nil {
+			continue
+		}
+		ids = append(ids, id)
+		guis[id] = t.Value.GloballyUniqueIdentifier.Value
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		if guis[id] == "" {
+			continue
+		}
+		if _, err := ParseGUI(guis[id]); err != nil {
+			name := "MerchantAccountInformation"
+			if _, ok := c.UnreservedTemplates[id]; ok {
+				name = "UnreservedTemplate"
+			}
+			return fmt.Errorf("%s %s: %s", name, id, err)
+		}
+	}
+	return nil
+}
+
+// isGUITemplate reports whether subtag 00 of template id is a Globally Unique
+// Identifier.
+func isGUITemplate(id ID) bool {
+	if within, err := id.Between(IDMerchantAccountInformationTemplateRangeStart, IDMerchantAccountInformationTemplateRangeEnd); err == nil && within {
+		return true
+	}
+	within, err := id.Between(IDUnreservedTemplatesRangeStart, IDUnreservedTemplatesRangeEnd)
+	return err == nil && within
+}
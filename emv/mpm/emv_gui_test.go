@@ -0,0 +1,175 @@
+package mpm
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseGUI(t *testing.T) {
+	tests := []struct {
+		name     string
+		gui      string
+		want     GUI
+		wantKind GUIKind
+		wantErr  bool
+	}{
+		{
+			name:     "AID",
+			gui:      "a0000000031010",
+			want:     "A0000000031010",
+			wantKind: GUIAID,
+		},
+		{
+			name:     "national AID",
+			gui:      "D15600000000",
+			want:     "D15600000000",
+			wantKind: GUIAID,
+		},
+		{
+			name:     "UUID",
+			gui:      "6ba7b8109dad11d180b400c04fd430c8",
+			want:     "6BA7B8109DAD11D180B400C04FD430C8",
+			wantKind: GUIUUID,
+		},
+		{
+			name:     "AID of 16 bytes",
+			gui:      "A0000000031010000000000000000000",
+			want:     "A0000000031010000000000000000000",
+			wantKind: GUIAID,
+		},
+		{
+			name:     "reverse domain name",
+			gui:      "BR.GOV.BCB.PIX",
+			want:     "br.gov.bcb.pix",
+			wantKind: GUIReverseDomain,
+		},
+		{
+			name:    "short",
+			gui:     "abcd",
+			wantErr: true,
+		},
+		{
+			name:    "odd length AID",
+			gui:     "A000000003101",
+			wantErr: true,
+		},
+		{
+			name:    "UUID with hyphens",
+			gui:     "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+			wantErr: true,
+		},
+		{
+			name:    "numeric top level domain",
+			gui:     "123.example",
+			wantErr: true,
+		},
+		{
+			name:    "empty label",
+			gui:     "com..example",
+			wantErr: true,
+		},
+		{
+			name:    "too long",
+			gui:     "com.example.loyalty.points.balance",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseGUI(tt.gui)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseGUI() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseGUI() = %v, want %v", got, tt.want)
+			}
+			if !tt.wantErr && got.Kind() != tt.wantKind {
+				t.Errorf("GUI.Kind() = %v, want %v", got.Kind(), tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestEMVQR_validateGUIs(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		wantErr bool
+	}{
+		{
+			name:    "AID in a merchant account information template",
+			payload: withCRC(decodeBody + "26180014A0000000031010"),
+		},
+		{
+			name:    "reverse domain name in an unreserved template",
+			payload: withCRC(decodeBody + "80120008com.abcd"),
+		},
+		{
+			name:    "invalid GUI in a merchant account information template",
+			payload: withCRC(decodeBody + "26080004abcd"),
+			wantErr: true,
+		},
+		{
+			name:    "invalid GUI in an unreserved template",
+			payload: withCRC(decodeBody + "80080004abcd"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qr, err := Decode(tt.payload)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if _, err := Encode(qr); err != nil {
+				t.Errorf("Encode() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestNewUUIDGUI(t *testing.T) {
+	g, err := NewUUIDGUI(bytes.NewReader(make([]byte, 16)))
+	if err != nil {
+		t.Fatalf("NewUUIDGUI() error = %v", err)
+	}
+	if want := GUI("00000000000040008000000000000000"); g != want {
+		t.Errorf("NewUUIDGUI() = %v, want %v", g, want)
+	}
+	if g.Kind() != GUIUUID {
+		t.Errorf("GUI.Kind() = %v, want %v", g.Kind(), GUIUUID)
+	}
+	if _, err := NewUUIDGUI(bytes.NewReader(nil)); err == nil {
+		t.Errorf("NewUUIDGUI() of a short reader should fail")
+	}
+}
+
+func TestNewReverseDomainGUI(t *testing.T) {
+	tests := []struct {
+		name    string
+		domain  string
+		names   []string
+		want    GUI
+		wantErr bool
+	}{
+		{name: "domain", domain: "example.com", want: "com.example"},
+		{name: "names", domain: "Example.com", names: []string{"loyalty"}, want: "com.example.loyalty"},
+		{name: "no domain", wantErr: true},
+		{name: "invalid label", domain: "example.com", names: []string{"loyalty points"}, wantErr: true},
+		{name: "too long", domain: "example.com", names: []string{"loyalty", "points", "balance"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewReverseDomainGUI(tt.domain, tt.names...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewReverseDomainGUI() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NewReverseDomainGUI() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package mpm
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Length limits of the Merchant Information—Language Template (ID "64")
+const (
+	MerchantInformationLanguagePreferenceLength = 2
+	MerchantInformationMerchantNameMaxLength    = 25
+	MerchantInformationMerchantCityMaxLength    = 15
+)
+
+// iso6391 is the set of ISO 639-1 language codes.
+var iso6391 = func() map[string]bool {
+	codes := "aa ab ae af ak am an ar as av ay az ba be bg bh bi bm bn bo br bs ca ce ch co cr cs cu cv cy " +
+		"da de dv dz ee el en eo es et eu fa ff fi fj fo fr fy ga gd gl gn gu gv ha he hi ho hr ht hu hy hz " +
+		"ia id ie ig ii ik io is it iu ja jv ka kg ki kj kk kl km kn ko kr ks ku kv kw ky la lb lg li ln lo " +
+		"lt lu lv mg mh mi mk ml mn mr ms mt my na nb nd ne ng nl nn no nr nv ny oc oj om or os pa pi pl ps " +
+		"pt qu rm rn ro ru rw sa sc sd se sg si sk sl sm sn so sq sr ss st su sv sw ta te tg th ti tk tl tn " +
+		"to tr ts tt tw ty ug uk ur uz ve vi vo wa wo xh yi yo za zh zu"
+	m := make(map[string]bool)
+	for _, code := range strings.Fields(codes) {
+		m[code] = true
+	}
+	return m
+}()
+
+// IsISO6391 reports whether v is an ISO 639-1 language code. The comparison is case-insensitive.
+func IsISO6391(v string) bool {
+	return iso6391[strings.ToLower(v)]
+}
+
+// MerchantInformation is the merchant name and city to display to the consumer.
+// Language is the lower-cased ISO 639-1 code of the alternate language, or empty
+// when the default Merchant Name (59) and Merchant City (60) are used.
+type MerchantInformation struct {
+	Language string
+	Name     string
+	City     string
+}
+
+// ResolveMerchantInformation returns the merchant name and city for the consumer
+// locales (e.g. "zh-Hant-TW", "ja_JP", "en"). The Merchant Information—Language
+// Template (64) is used when its Language Preference matches the primary language
+// of one of the locales; otherwise Merchant Name (59) and Merchant City (60) are used.
+// Merchant City falls back to 60 when 64.02 is absent.
+func (c *EMVQR) ResolveMerchantInformation(locales ...string) MerchantInformation {
+	info := MerchantInformation{
+		Name: c.MerchantName.Value,
+		City: c.MerchantCity.Value,
+	}
+	t := c.MerchantInformationLanguageTemplate
+	if t == nil || t.MerchantName.Value == "" || !IsISO6391(t.LanguagePreference.Value) {
+		return info
+	}
+	language := strings.ToLower(t.LanguagePreference.Value)
+	for _, locale := range locales {
+		if primaryLanguage(locale) != language {
+			continue
+		}
+		info.Language = language
+		info.Name = t.MerchantName.Value
+		if t.MerchantCity.Value != "" {
+			info.City = t.MerchantCity.Value
+		}
+		break
+	}
+	return info
+}
+
+func primaryLanguage(locale string) string {
+	if i := strings.IndexAny(locale, "-_."); i >= 0 {
+		locale = locale[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(locale))
+}
+
+func (s *MerchantInformationLanguageTemplate) validateLanguage() error {
+	if utf8.RuneCountInString(s.LanguagePreference.Value) != MerchantInformationLanguagePreferenceLength || !IsISO6391(s.LanguagePreference.Value) {
+		return fmt.Errorf("LanguagePreference should be an ISO 639-1 code, LanguagePreference: %s", s.LanguagePreference.Value)
+	}
+	if utf8.RuneCountInString(s.MerchantName.Value) > MerchantInformationMerchantNameMaxLength {
+		return fmt.Errorf("MerchantName should be at most %d characters, MerchantName: %s", MerchantInformationMerchantNameMaxLength, s.MerchantName.Value)
+	}
+	if utf8.RuneCountInString(s.MerchantCity.Value) > MerchantInformationMerchantCityMaxLength {
+		return fmt.Errorf("MerchantCity should be at most %d characters, MerchantCity: %s", MerchantInformationMerchantCityMaxLength, s.MerchantCity.Value)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package mpm
+
+import (
+	"testing"
+)
+
+func TestEMVQR_ResolveMerchantInformation(t *testing.T) {
+	emvqr := &EMVQR{}
+	emvqr.SetMerchantName("BEST TRANSPORT")
+	emvqr.SetMerchantCity("BEIJING")
+	language := &MerchantInformationLanguageTemplate{}
+	language.SetLanguagePreference("ZH")
+	language.SetMerchantName("最佳运输")
+	language.SetMerchantCity("北京")
+	emvqr.SetMerchantInformationLanguageTemplate(language)
+
+	withoutCity := &EMVQR{}
+	withoutCity.SetMerchantName("BEST TRANSPORT")
+	withoutCity.SetMerchantCity("BEIJING")
+	languageWithoutCity := &MerchantInformationLanguageTemplate{}
+	languageWithoutCity.SetLanguagePreference("zh")
+	languageWithoutCity.SetMerchantName("最佳运输")
+	withoutCity.SetMerchantInformationLanguageTemplate(languageWithoutCity)
+
+	tests := []struct {
+		name    string
+		emvqr   *EMVQR
+		locales []string
+		want    MerchantInformation
+	}{
+		{
+			name:    "matching locale",
+			emvqr:   emvqr,
+			locales: []string{"zh-Hans-CN"},
+			want:    MerchantInformation{Language: "zh", Name: "最佳运输", City: "北京"},
+		},
+		{
+			name:    "matching second locale",
+			emvqr:   emvqr,
+			locales: []string{"fr_FR", "zh_TW"},
+			want:    MerchantInformation{Language: "zh", Name: "最佳运输", City: "北京"},
+		},
+		{
+			name:    "no matching locale",
+			emvqr:   emvqr,
+			locales: []string{"en-US", "ja"},
+			want:    MerchantInformation{Name: "BEST TRANSPORT", City: "BEIJING"},
+		},
+		{
+			name:    "no locale",
+			emvqr:   emvqr,
+			locales: nil,
+			want:    MerchantInformation{Name: "BEST TRANSPORT", City: "BEIJING"},
+		},
+		{
+			name:    "city falls back to default",
+			emvqr:   withoutCity,
+			locales: []string{"ZH"},
+			want:    MerchantInformation{Language: "zh", Name: "最佳运输", City: "BEIJING"},
+		},
+		{
+			name:    "without language template",
+			emvqr:   &EMVQR{MerchantName: TLV{Tag: IDMerchantName, Length: "06", Value: "DONGRI"}},
+			locales: []string{"ja"},
+			want:    MerchantInformation{Name: "DONGRI"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.emvqr.ResolveMerchantInformation(tt.locales...); got != tt.want {
+				t.Errorf("EMVQR.ResolveMerchantInformation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerchantInformationLanguageTemplate_Validate(t *testing.T) {
+	tests := []struct {
+		name               string
+		languagePreference string
+		merchantName       string
+		merchantCity       string
+		wantErr            bool
+	}{
+		{
+			name:               "ok",
+			languagePreference: "ZH",
+			merchantName:       "最佳运输",
+			merchantCity:       "北京",
+		},
+		{
+			name:               "not ISO 639-1",
+			languagePreference: "JP",
+			merchantName:       "ドングリ",
+			wantErr:            true,
+		},
+		{
+			name:               "three letters",
+			languagePreference: "jpn",
+			merchantName:       "ドングリ",
+			wantErr:            true,
+		},
+		{
+			name:               "too long merchant name",
+			languagePreference: "ja",
+			merchantName:       "あいうえおかきくけこさしすせそたちつてとなにぬねのは",
+			wantErr:            true,
+		},
+		{
+			name:               "too long merchant city",
+			languagePreference: "ja",
+			merchantName:       "ドングリ",
+			merchantCity:       "あいうえおかきくけこさしすせそた",
+			wantErr:            true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &MerchantInformationLanguageTemplate{}
+			s.SetLanguagePreference(tt.languagePreference)
+			s.SetMerchantName(tt.merchantName)
+			s.SetMerchantCity(tt.merchantCity)
+			if err := s.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("MerchantInformationLanguageTemplate.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
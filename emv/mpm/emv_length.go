@@ -0,0 +1,248 @@
+package mpm
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// LengthPolicy tells how the two digit length of a data object is counted.
+// EMVCo counts characters, but some acquirers and older wallets count UTF-8 bytes,
+// which only makes a difference for non-ASCII values such as template 64.
+// The Length of a TLV in EMVQR is always counted in characters; the policy only
+// applies to the payload.
+type LengthPolicy int
+
+// const ...
+const (
+	LengthPolicyAuto  LengthPolicy = iota // detect from the payload, parsers count characters
+	LengthPolicyRunes                     // count characters (runes)
+	LengthPolicyBytes                     // count UTF-8 bytes
+)
+
+func (p LengthPolicy) String() string {
+	switch p {
+	case LengthPolicyAuto:
+		return "auto"
+	case LengthPolicyRunes:
+		return "runes"
+	case LengthPolicyBytes:
+		return "bytes"
+	}
+	return fmt.Sprintf("LengthPolicy(%d)", int(p))
+}
+
+// Count returns the length of v under the policy.
+func (p LengthPolicy) Count(v string) int {
+	if p == LengthPolicyBytes {
+		return len(v)
+	}
+	return utf8.RuneCountInString(v)
+}
+
+// NewParserWithLengthPolicy ...
+func NewParserWithLengthPolicy(payload string, policy LengthPolicy) *Parser {
+	p := NewParser(payload)
+	p.policy = policy
+	if policy == LengthPolicyBytes {
+		p.text = payload
+		p.offsets = make([]int64, 0, p.max+1)
+		for i := range payload {
+			p.offsets = append(p.offsets, int64(i))
+		}
+		p.offsets = append(p.offsets, int64(len(payload)))
+	}
+	return p
+}
+
+// DetectLengthPolicy returns the length policy a payload is encoded with.
+// ASCII payloads and payloads that are well-formed either way are reported as
+// LengthPolicyRunes.
+func DetectLengthPolicy(payload string) LengthPolicy {
+	if isASCII(payload) {
+		return LengthPolicyRunes
+	}
+	return detectLengthPolicyBytes([]byte(payload))
+}
+
+// ParseEMVQRWithLengthPolicy ...
+func ParseEMVQRWithLengthPolicy(payload string, policy LengthPolicy) (*EMVQR, error) {
+	if policy == LengthPolicyAuto {
+		policy = DetectLengthPolicy(payload)
+	}
+	return parseEMVQR(NewParserWithLengthPolicy(payload, policy))
+}
+
+// GeneratePayloadWithLengthPolicy ...
+func (c *EMVQR) GeneratePayloadWithLengthPolicy(policy LengthPolicy) (string, error) {
+	payload, err := Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	if policy != LengthPolicyBytes || isASCII(payload) {
+		return payload, nil
+	}
+	// the CRC is the last data object of the generated payload
+	s, err := relength(payload[:len(payload)-len(IDCRC)-ValueLengthWordCount-4], LengthPolicyBytes, true)
+	if err != nil {
+		return "", err
+	}
+	return s + formatCrc(s), nil
+}
+
+// EncodeWithLengthPolicy ...
+func EncodeWithLengthPolicy(emvqr *EMVQR, policy LengthPolicy) (string, error) {
+	if err := emvqr.Validate(); err != nil {
+		return "", err
+	}
+	return emvqr.GeneratePayloadWithLengthPolicy(policy)
+}
+
+// isTemplate reports whether a root data object is a template.
+func isTemplate(id ID) bool {
+	if id == IDAdditionalDataFieldTemplate || id == IDMerchantInformationLanguageTemplate {
+		return true
+	}
+	if within, err := id.Between(IDMerchantAccountInformationTemplateRangeStart, IDMerchantAccountInformationTemplateRangeEnd); err == nil && within {
+		return true
+	}
+	if within, err := id.Between(IDUnreservedTemplatesRangeStart, IDUnreservedTemplatesRangeEnd); err == nil && within {
+		return true
+	}
+	return false
+}
+
+// relength re-encodes a payload written with character lengths using the policy.
+func relength(payload string, policy LengthPolicy, root bool) (string, error) {
+	p := NewParser(payload)
+	var sb strings.Builder
+	for p.Next() {
+		id := p.ID()
+		value := p.Value()
+		if p.Err() != nil {
+			break
+		}
+		if root && isTemplate(id) {
+			v, err := relength(value, policy, false)
+			if err != nil {
+				return "", err
+			}
+			value = v
+		}
+		length := policy.Count(value)
+		if length > 99 {
+			return "", fmt.Errorf("value of %s is too long for a two digit length, length: %d", id, length)
+		}
+		sb.WriteString(fmt.Sprintf("%s%02d%s", id.String(), length, value))
+	}
+	if err := p.Err(); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// isANS reports whether s contains only Alphanumeric Special characters (0x20-0x7E).
+func isANS(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7E {
+			return false
+		}
+	}
+	return true
+}
+
+func checkANS(path string, tlv TLV) error {
+	if !isANS(tlv.Value) {
+		return fmt.Errorf("value of %s should contain only alphanumeric special characters, value: %q", path, tlv.Value)
+	}
+	return nil
+}
+
+// validateCharacterSet checks that every value is ANS, except for the Merchant
+// Information—Language Template (64) which may contain any UTF-8 character.
+func (c *EMVQR) validateCharacterSet() error {
+	root := []TLV{
+		c.PayloadFormatIndicator,
+		c.PointOfInitiationMethod,
+		c.MerchantCategoryCode,
+		c.TransactionCurrency,
+		c.TransactionAmount,
+		c.TipOrConvenienceIndicator,
+		c.ValueOfConvenienceFeeFixed,
+		c.ValueOfConvenienceFeePercentage,
+		c.CountryCode,
+		c.MerchantName,
+		c.MerchantCity,
+		c.PostalCode,
+	}
+	root = append(root, c.RFUforEMVCo...)
+	for _, tlv := range root {
+		if err := checkANS(tlv.Tag.String(), tlv); err != nil {
+			return err
+		}
+	}
+	for id, m := range c.MerchantAccountInformation {
+		if m.Value == nil {
+			continue
+		}
+		tlvs := append([]TLV{{Value: m.Value.Value}, m.Value.GloballyUniqueIdentifier}, m.Value.PaymentNetworkSpecific...)
+		for _, tlv := range tlvs {
+			path := id.String()
+			if tlv.Tag != "" {
+				path += "." + tlv.Tag.String()
+			}
+			if err := checkANS(path, tlv); err != nil {
+				return err
+			}
+		}
+	}
+	if s := c.AdditionalDataFieldTemplate; s != nil {
+		tlvs := []TLV{
+			s.BillNumber,
+			s.MobileNumber,
+			s.StoreLabel,
+			s.LoyaltyNumber,
+			s.ReferenceLabel,
+			s.CustomerLabel,
+			s.TerminalLabel,
+			s.PurposeTransaction,
+			s.AdditionalConsumerDataRequest,
+		}
+		tlvs = append(tlvs, s.RFUforEMVCo...)
+		tlvs = append(tlvs, s.PaymentSystemSpecific...)
+		for _, tlv := range tlvs {
+			if err := checkANS(IDAdditionalDataFieldTemplate.String()+"."+tlv.Tag.String(), tlv); err != nil {
+				return err
+			}
+		}
+	}
+	if s := c.MerchantInformationLanguageTemplate; s != nil {
+		tlvs := append([]TLV{s.LanguagePreference, s.MerchantName, s.MerchantCity}, s.RFUforEMVCo...)
+		for _, tlv := range tlvs {
+			if !utf8.ValidString(tlv.Value) {
+				return fmt.Errorf("value of %s.%s should be valid UTF-8", IDMerchantInformationLanguageTemplate, tlv.Tag)
+			}
+		}
+	}
+	for id, u := range c.UnreservedTemplates {
+		if u.Value == nil {
+			continue
+		}
+		tlvs := append([]TLV{u.Value.GloballyUniqueIdentifier}, u.Value.ContextSpecificData...)
+		for _, tlv := range tlvs {
+			if err := checkANS(id.String()+"."+tlv.Tag.String(), tlv); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package mpm
+
+import (
+	"reflect"
+	"testing"
+)
+
+const (
+	languageTemplateRunes = "64200002ZH0104最佳运输0202北京"
+	languageTemplateBytes = "64320002ZH0112最佳运输0206北京"
+)
+
+func TestDetectLengthPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    LengthPolicy
+	}{
+		{
+			name:    "ascii",
+			payload: "0002015802JP",
+			want:    LengthPolicyRunes,
+		},
+		{
+			name:    "runes",
+			payload: "000201" + languageTemplateRunes + "6304ABCD",
+			want:    LengthPolicyRunes,
+		},
+		{
+			name:    "bytes",
+			payload: "000201" + languageTemplateBytes + "6304ABCD",
+			want:    LengthPolicyBytes,
+		},
+		{
+			name:    "broken",
+			payload: "0002016420最佳",
+			want:    LengthPolicyRunes,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectLengthPolicy(tt.payload); got != tt.want {
+				t.Errorf("DetectLengthPolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParser_LengthPolicyBytes(t *testing.T) {
+	tests := []struct {
+		name       string
+		payload    string
+		wantValues []string
+		wantErr    bool
+	}{
+		{
+			name:       "ok",
+			payload:    "0112最佳运输0206北京",
+			wantValues: []string{"最佳运输", "北京"},
+		},
+		{
+			name:       "split character",
+			payload:    "0104最佳运输",
+			wantValues: nil,
+			wantErr:    true,
+		},
+		{
+			name:       "out of range",
+			payload:    "0113最佳运输",
+			wantValues: nil,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParserWithLengthPolicy(tt.payload, LengthPolicyBytes)
+			var values []string
+			for p.Next() {
+				v := p.Value()
+				if p.Err() != nil {
+					break
+				}
+				values = append(values, v)
+			}
+			if (p.Err() != nil) != tt.wantErr {
+				t.Errorf("Parser.Err() error = %v, wantErr %v", p.Err(), tt.wantErr)
+			}
+			if !reflect.DeepEqual(values, tt.wantValues) {
+				t.Errorf("Parser.Value() = %v, want %v", values, tt.wantValues)
+			}
+		})
+	}
+}
+
+func TestEMVQR_GeneratePayloadWithLengthPolicy(t *testing.T) {
+	emvqr, err := ParseEMVQR("000201" + languageTemplateRunes)
+	if err != nil {
+		t.Fatalf("ParseEMVQR() error = %v", err)
+	}
+	tests := []struct {
+		name   string
+		policy LengthPolicy
+		want   string
+	}{
+		{
+			name:   "runes",
+			policy: LengthPolicyRunes,
+			want:   "000201" + languageTemplateRunes + formatCrc("000201"+languageTemplateRunes),
+		},
+		{
+			name:   "bytes",
+			policy: LengthPolicyBytes,
+			want:   "000201" + languageTemplateBytes + formatCrc("000201"+languageTemplateBytes),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := emvqr.GeneratePayloadWithLengthPolicy(tt.policy)
+			if err != nil {
+				t.Fatalf("EMVQR.GeneratePayloadWithLengthPolicy() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("EMVQR.GeneratePayloadWithLengthPolicy() = %v, want %v", got, tt.want)
+			}
+			decoded, err := ParseEMVQR(got)
+			if err != nil {
+				t.Fatalf("ParseEMVQR() error = %v", err)
+			}
+			if !reflect.DeepEqual(decoded.MerchantInformationLanguageTemplate, emvqr.MerchantInformationLanguageTemplate) {
+				t.Errorf("ParseEMVQR() = %v, want %v", decoded.MerchantInformationLanguageTemplate, emvqr.MerchantInformationLanguageTemplate)
+			}
+		})
+	}
+}
+
+func TestEMVQR_validateCharacterSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		emvqr   func() *EMVQR
+		wantErr bool
+	}{
+		{
+			name: "ok",
+			emvqr: func() *EMVQR {
+				c := &EMVQR{}
+				c.SetMerchantName("BEST TRANSPORT")
+				language := &MerchantInformationLanguageTemplate{}
+				language.SetMerchantName("最佳运输")
+				c.SetMerchantInformationLanguageTemplate(language)
+				return c
+			},
+		},
+		{
+			name: "non-ANS merchant name",
+			emvqr: func() *EMVQR {
+				c := &EMVQR{}
+				c.SetMerchantName("最佳运输")
+				return c
+			},
+			wantErr: true,
+		},
+		{
+			name: "non-ANS additional data",
+			emvqr: func() *EMVQR {
+				c := &EMVQR{}
+				additional := &AdditionalDataFieldTemplate{}
+				additional.SetStoreLabel("店舗")
+				c.SetAdditionalDataFieldTemplate(additional)
+				return c
+			},
+			wantErr: true,
+		},
+		{
+			name: "control character in merchant account information",
+			emvqr: func() *EMVQR {
+				c := &EMVQR{}
+				c.AddMerchantAccountInformation("02", &MerchantAccountInformation{Value: "4111\n"})
+				return c
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.emvqr().validateCharacterSet(); (err != nil) != tt.wantErr {
+				t.Errorf("EMVQR.validateCharacterSet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,476 @@
+package mpm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Marshaler is implemented by types that encode their own value.
+// An empty value leaves the data object out.
+type Marshaler interface {
+	MarshalEMV() (string, error)
+}
+
+// Unmarshaler is implemented by types that decode their own value.
+type Unmarshaler interface {
+	UnmarshalEMV(value string) error
+}
+
+// decodeHook is implemented by templates that derive data once decoded.
+type decodeHook interface {
+	decoded() error
+}
+
+var (
+	marshalerType   = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	idType          = reflect.TypeOf(ID(""))
+)
+
+// Marshal returns the payload of v, a struct or a pointer to a struct whose
+// fields tagged `emv:"..."` are data objects. The tag is the ID, or a range of
+// IDs such as "65-79", followed by options:
+//
+//	template       the value is a template of the data objects of a struct
+//	template=NN-MM only the IDs from NN to MM are templates, the others are
+//	               primitive and decoded through Unmarshaler
+//	max=N          the value is at most N characters
+//	crc            the CRC (63), computed over the payload and written last
+//
+// Fields are strings, types implementing Marshaler and Unmarshaler, structs or
+// pointers to structs for templates, and data object structs, such as TLV,
+// with Tag (ID), Length (string) and Value fields. A range of IDs is held in a
+// slice of data object structs or in a map keyed by ID. Data objects are
+// written in ID order and empty values are left out, but a non-nil pointer to
+// a template is written even when empty. A template type implementing
+// Marshaler encodes itself. Untagged fields are ignored.
+func Marshal(v interface{}) (string, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return "", fmt.Errorf("mpm: Marshal of %T, want a struct or a pointer to a struct", v)
+	}
+	return marshalTemplate(rv, true)
+}
+
+// Unmarshal parses payload into v, a pointer to a struct tagged as described
+// by Marshal. Unknown IDs are skipped, and the last of duplicate IDs wins
+// unless its field is a slice. Unmarshal neither checks the CRC nor validates.
+func Unmarshal(payload string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("mpm: Unmarshal into %T, want a non-nil pointer to a struct", v)
+	}
+	return unmarshalTemplate(NewParserWithLengthPolicy(payload, DetectLengthPolicy(payload)), rv.Elem())
+}
+
+// field is a field tagged `emv:"..."`.
+type field struct {
+	name          string
+	index         int
+	start, end    ID
+	template      bool
+	templateStart ID
+	templateEnd   ID
+	max           int
+	crc           bool
+}
+
+func (f *field) contains(id ID) bool {
+	return id >= f.start && id <= f.end
+}
+
+func (f *field) isTemplate(id ID) bool {
+	return f.template && id >= f.templateStart && id <= f.templateEnd
+}
+
+var fieldCache sync.Map // map[reflect.Type][]field
+
+// typeFields returns the tagged fields of a struct type.
+func typeFields(typ reflect.Type) ([]field, error) {
+	if fields, ok := fieldCache.Load(typ); ok {
+		return fields.([]field), nil
+	}
+	var fields []field
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		tag, ok := sf.Tag.Lookup("emv")
+		if !ok {
+			continue
+		}
+		f, err := parseFieldTag(sf.Name, tag)
+		if err != nil {
+			return nil, fmt.Errorf("mpm: %s.%s: %s", typ, sf.Name, err)
+		}
+		f.index = i
+		if sf.PkgPath != "" {
+			return nil, fmt.Errorf("mpm: %s.%s should be exported", typ, sf.Name)
+		}
+		if err := checkFieldType(sf.Type, f); err != nil {
+			return nil, fmt.Errorf("mpm: %s.%s: %s", typ, sf.Name, err)
+		}
+		for _, other := range fields {
+			if f.start <= other.end && other.start <= f.end {
+				return nil, fmt.Errorf("mpm: %s.%s and %s.%s have the same ID", typ, other.name, typ, sf.Name)
+			}
+		}
+		fields = append(fields, f)
+	}
+	fieldCache.Store(typ, fields)
+	return fields, nil
+}
+
+func parseFieldTag(name, tag string) (field, error) {
+	f := field{name: name}
+	options := strings.Split(tag, ",")
+	var err error
+	if f.start, f.end, err = parseIDRange(options[0]); err != nil {
+		return f, err
+	}
+	for _, option := range options[1:] {
+		switch {
+		case option == "template":
+			f.template, f.templateStart, f.templateEnd = true, f.start, f.end
+		case strings.HasPrefix(option, "template="):
+			f.template = true
+			if f.templateStart, f.templateEnd, err = parseIDRange(strings.TrimPrefix(option, "template=")); err != nil {
+				return f, err
+			}
+		case strings.HasPrefix(option, "max="):
+			if f.max, err = strconv.Atoi(strings.TrimPrefix(option, "max=")); err != nil || f.max < 1 || f.max > 99 {
+				return f, fmt.Errorf("max should be 1 to 99, max: %s", strings.TrimPrefix(option, "max="))
+			}
+		case option == "crc":
+			f.crc = true
+		default:
+			return f, fmt.Errorf("unknown option %s", option)
+		}
+	}
+	if f.crc && (f.start != IDCRC || f.end != IDCRC) {
+		return f, fmt.Errorf("crc should be the option of ID %s, ID: %s", IDCRC, options[0])
+	}
+	return f, nil
+}
+
+// parseIDRange parses "NN" or "NN-MM".
+func parseIDRange(s string) (ID, ID, error) {
+	start, end := s, s
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		start, end = s[:i], s[i+1:]
+	}
+	if !isNumericID(ID(start)) || !isNumericID(ID(end)) || end < start {
+		return "", "", fmt.Errorf("ID should be NN or NN-MM, ID: %s", s)
+	}
+	return ID(start), ID(end), nil
+}
+
+// checkFieldType reports whether values of typ can be data objects of f.
+func checkFieldType(typ reflect.Type, f field) error {
+	switch {
+	case f.crc:
+		if typ.Kind() == reflect.String || isDataObject(typ) {
+			return nil
+		}
+	case typ.Kind() == reflect.Map:
+		if typ.Key() == idType {
+			return checkValueType(typ.Elem(), f)
+		}
+	case typ.Kind() == reflect.Slice:
+		// IDs of a range come from the elements
+		if isDataObject(typ.Elem()) || f.start == f.end {
+			return checkValueType(typ.Elem(), f)
+		}
+	case f.start != f.end:
+		return fmt.Errorf("a range of IDs should be held in a slice of data objects or a map, type: %s", typ)
+	default:
+		return checkValueType(typ, f)
+	}
+	return fmt.Errorf("unsupported type %s", typ)
+}
+
+func checkValueType(typ reflect.Type, f field) error {
+	if isDataObject(typ) {
+		typ = typ.Field(dataObjectValue).Type
+	}
+	primitive := !f.template || f.start < f.templateStart || f.end > f.templateEnd
+	if f.template && structType(typ) == nil {
+		return fmt.Errorf("a template should be a struct or a pointer to a struct, type: %s", typ)
+	}
+	if primitive && typ.Kind() != reflect.String && !implements(typ, marshalerType) && !implements(typ, unmarshalerType) {
+		return fmt.Errorf("unsupported type %s", typ)
+	}
+	if st := structType(typ); f.template && st != nil {
+		if _, err := typeFields(st); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// data object struct fields
+const (
+	dataObjectTag = iota
+	dataObjectLength
+	dataObjectValue
+)
+
+// isDataObject reports whether typ is a struct of Tag (ID), Length (string)
+// and Value, such as TLV.
+func isDataObject(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct || typ.NumField() != 3 {
+		return false
+	}
+	return typ.Field(dataObjectTag).Name == "Tag" && typ.Field(dataObjectTag).Type == idType &&
+		typ.Field(dataObjectLength).Name == "Length" && typ.Field(dataObjectLength).Type.Kind() == reflect.String &&
+		typ.Field(dataObjectValue).Name == "Value"
+}
+
+// structType returns the struct type of a struct or a pointer to a struct.
+func structType(typ reflect.Type) reflect.Type {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+	return typ
+}
+
+func implements(typ, iface reflect.Type) bool {
+	return typ.Implements(iface) || typ.Kind() != reflect.Ptr && reflect.PtrTo(typ).Implements(iface)
+}
+
+// marshalTemplate returns the data objects of the tagged fields of rv, a
+// struct, followed by the CRC if root.
+func marshalTemplate(rv reflect.Value, root bool) (string, error) {
+	fields, err := typeFields(rv.Type())
+	if err != nil {
+		return "", err
+	}
+	type dataObject struct {
+		id    ID
+		value string
+	}
+	var (
+		dataObjects []dataObject
+		crc         bool
+	)
+	add := func(f *field, id ID, v reflect.Value) error {
+		// IDs of maps and data object slices come from the values
+		if !isNumericID(id) || !f.contains(id) {
+			return fmt.Errorf("mpm: %s: ID should be two digits from %s to %s, ID: %s", f.name, f.start, f.end, id)
+		}
+		value, ok, err := marshalValue(f, id, v)
+		if err != nil || !ok {
+			return err
+		}
+		dataObjects = append(dataObjects, dataObject{id, value})
+		return nil
+	}
+	for i := range fields {
+		f := &fields[i]
+		fv := rv.Field(f.index)
+		switch {
+		case f.crc:
+			crc = true
+		case fv.Kind() == reflect.Map:
+			for _, key := range fv.MapKeys() {
+				if err := add(f, ID(key.String()), fv.MapIndex(key)); err != nil {
+					return "", err
+				}
+			}
+		case fv.Kind() == reflect.Slice:
+			for j := 0; j < fv.Len(); j++ {
+				id := f.start
+				if isDataObject(fv.Type().Elem()) {
+					id = ID(fv.Index(j).Field(dataObjectTag).String())
+				}
+				if err := add(f, id, fv.Index(j)); err != nil {
+					return "", err
+				}
+			}
+		default:
+			if err := add(f, f.start, fv); err != nil {
+				return "", err
+			}
+		}
+	}
+	sort.SliceStable(dataObjects, func(i, j int) bool { return dataObjects[i].id < dataObjects[j].id })
+	var sb strings.Builder
+	for _, d := range dataObjects {
+		sb.WriteString(format(d.id, d.value))
+	}
+	s := sb.String()
+	if root && crc {
+		s += formatCrc(s)
+	}
+	return s, nil
+}
+
+// marshalValue returns the value of the data object id of field f, and false
+// if it is left out. A Marshaler takes precedence over the template of f, so
+// that a template type may encode itself, as MerchantAccountInformation does.
+func marshalValue(f *field, id ID, v reflect.Value) (string, bool, error) {
+	if isDataObject(v.Type()) {
+		v = v.Field(dataObjectValue)
+	}
+	// a template set through a pointer is encoded even when it is empty
+	keep := v.Kind() == reflect.Ptr && f.isTemplate(id)
+	var value string
+	switch {
+	case implements(v.Type(), marshalerType):
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return "", false, nil
+		}
+		if !v.Type().Implements(marshalerType) {
+			// pointer receiver
+			p := reflect.New(v.Type())
+			p.Elem().Set(v)
+			v = p
+		}
+		var err error
+		if value, err = v.Interface().(Marshaler).MarshalEMV(); err != nil {
+			return "", false, fmt.Errorf("mpm: %s: %w", f.name, err)
+		}
+	case f.isTemplate(id):
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return "", false, nil
+			}
+			v = v.Elem()
+		}
+		var err error
+		if value, err = marshalTemplate(v, false); err != nil {
+			return "", false, err
+		}
+	case v.Kind() == reflect.String:
+		value = v.String()
+	default:
+		return "", false, fmt.Errorf("mpm: %s: unsupported type %s", f.name, v.Type())
+	}
+	if f.max > 0 && utf8.RuneCountInString(value) > f.max {
+		return "", false, fmt.Errorf("mpm: %s (%s) should be at most %d characters, length: %d", f.name, id, f.max, utf8.RuneCountInString(value))
+	}
+	return value, value != "" || keep, nil
+}
+
+// unmarshalTemplate parses the data objects of p into the tagged fields of
+// rv, a struct.
+func unmarshalTemplate(p *Parser, rv reflect.Value) error {
+	fields, err := typeFields(rv.Type())
+	if err != nil {
+		return err
+	}
+	for p.Next() {
+		id := p.ID()
+		value := p.Value()
+		if p.Err() != nil {
+			break
+		}
+		if !isNumericID(id) {
+			return p.invalidIDError()
+		}
+		f := lookupField(fields, id)
+		if f == nil {
+			continue
+		}
+		fv := rv.Field(f.index)
+		switch fv.Kind() {
+		case reflect.Map:
+			v, err := unmarshalValue(p, f, id, value, fv.Type().Elem())
+			if err != nil {
+				return err
+			}
+			if fv.IsNil() {
+				fv.Set(reflect.MakeMap(fv.Type()))
+			}
+			fv.SetMapIndex(reflect.ValueOf(id).Convert(fv.Type().Key()), v)
+		case reflect.Slice:
+			v, err := unmarshalValue(p, f, id, value, fv.Type().Elem())
+			if err != nil {
+				return err
+			}
+			fv.Set(reflect.Append(fv, v))
+		default:
+			v, err := unmarshalValue(p, f, id, value, fv.Type())
+			if err != nil {
+				return err
+			}
+			fv.Set(v)
+		}
+	}
+	if err := p.Err(); err != nil {
+		return err
+	}
+	if hook, ok := rv.Addr().Interface().(decodeHook); ok {
+		if err := hook.decoded(); err != nil {
+			e := p.templateError(fnExtension, err)
+			if p.decode != nil {
+				return p.decode.report(e)
+			}
+			return e
+		}
+	}
+	return nil
+}
+
+func lookupField(fields []field, id ID) *field {
+	for i := range fields {
+		if fields[i].contains(id) {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+// unmarshalValue returns the value of typ of the data object id of field f.
+func unmarshalValue(p *Parser, f *field, id ID, value string, typ reflect.Type) (reflect.Value, error) {
+	if isDataObject(typ) {
+		d := reflect.New(typ).Elem()
+		d.Field(dataObjectTag).SetString(string(id))
+		d.Field(dataObjectLength).SetString(l(value))
+		v, err := unmarshalValue(p, f, id, value, typ.Field(dataObjectValue).Type)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		d.Field(dataObjectValue).Set(v)
+		return d, nil
+	}
+	if f.max > 0 && utf8.RuneCountInString(value) > f.max {
+		return reflect.Value{}, fmt.Errorf("mpm: %s (%s) should be at most %d characters, length: %d", f.name, id, f.max, utf8.RuneCountInString(value))
+	}
+	if f.isTemplate(id) {
+		t := reflect.New(structType(typ))
+		if err := unmarshalTemplate(p.child(value), t.Elem()); err != nil {
+			return reflect.Value{}, err
+		}
+		if typ.Kind() == reflect.Ptr {
+			return t, nil
+		}
+		return t.Elem(), nil
+	}
+	if implements(typ, unmarshalerType) {
+		v := reflect.New(typ)
+		u := v
+		if typ.Kind() == reflect.Ptr {
+			v.Elem().Set(reflect.New(typ.Elem()))
+			u = v.Elem()
+		}
+		if err := u.Interface().(Unmarshaler).UnmarshalEMV(value); err != nil {
+			return reflect.Value{}, fmt.Errorf("mpm: %s (%s): %w", f.name, id, err)
+		}
+		return v.Elem(), nil
+	}
+	if typ.Kind() == reflect.String {
+		return reflect.ValueOf(value).Convert(typ), nil
+	}
+	return reflect.Value{}, errors.New("mpm: unsupported type " + typ.String())
+}
@@ -0,0 +1,224 @@
+package mpm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// testAmount is an amount in cents.
+type testAmount int64
+
+func (a testAmount) MarshalEMV() (string, error) {
+	if a < 0 {
+		return "", errors.New("negative amount")
+	}
+	if a == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("%d.%02d", a/100, a%100), nil
+}
+
+func (a *testAmount) UnmarshalEMV(value string) error {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return err
+	}
+	*a = testAmount(math.Round(f * 100))
+	return nil
+}
+
+type testAdditionalData struct {
+	BillNumber     string `emv:"01"`
+	ReferenceLabel string `emv:"05,max=25"`
+	Other          []TLV  `emv:"10-99"`
+}
+
+type testQR struct {
+	PayloadFormatIndicator string                               `emv:"00"`
+	MerchantAccounts       map[ID]MerchantAccountInformationTLV `emv:"02-51,template=26-51"`
+	TransactionAmount      testAmount                           `emv:"54"`
+	MerchantName           string                               `emv:"59,max=25"`
+	AdditionalData         *testAdditionalData                  `emv:"62,template"`
+	CRC                    string                               `emv:"63,crc"`
+	Note                   string
+}
+
+func TestMarshal(t *testing.T) {
+	mai := &MerchantAccountInformation{}
+	mai.SetGloballyUniqueIdentifier("A0000000031010")
+	qr := testQR{
+		PayloadFormatIndicator: "01",
+		MerchantAccounts: map[ID]MerchantAccountInformationTLV{
+			"26": {Value: mai},
+			"02": {Value: &MerchantAccountInformation{Value: "4111111111111111"}},
+		},
+		TransactionAmount: 1250,
+		MerchantName:      "BEST TRANSPORT",
+		AdditionalData: &testAdditionalData{
+			ReferenceLabel: "REF",
+			Other:          []TLV{{Tag: "50", Value: "PSS"}, {Tag: "10", Value: "RFU"}},
+		},
+		Note: "ignored",
+	}
+	body := "000201" + "02164111111111111111" + "26180014A0000000031010" + "540512.50" + "5914BEST TRANSPORT" + "62210503REF1003RFU5003PSS"
+	want := withCRC(body)
+	got, err := Marshal(&qr)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if got != want {
+		t.Fatalf("Marshal() = %v, want %v", got, want)
+	}
+
+	var decoded testQR
+	if err := Unmarshal(got, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	qr.Note = ""
+	qr.CRC = want[len(want)-4:]
+	// decoding sets the Tag and Length of the data objects
+	qr.MerchantAccounts["02"] = MerchantAccountInformationTLV{Tag: "02", Length: "16", Value: qr.MerchantAccounts["02"].Value}
+	qr.MerchantAccounts["26"] = MerchantAccountInformationTLV{Tag: "26", Length: "18", Value: mai}
+	qr.AdditionalData.Other = []TLV{{Tag: "10", Length: "03", Value: "RFU"}, {Tag: "50", Length: "03", Value: "PSS"}}
+	if !reflect.DeepEqual(decoded, qr) {
+		t.Errorf("Unmarshal() = %+v, want %+v", decoded, qr)
+	}
+}
+
+func TestMarshal_EMVQR(t *testing.T) {
+	c, err := ParseEMVQR(withCRC(decodeBody + "62070503REF"))
+	if err != nil {
+		t.Fatalf("ParseEMVQR() error = %v", err)
+	}
+	got, err := Marshal(c)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if got != c.GeneratePayload() {
+		t.Errorf("Marshal() = %v, want %v", got, c.GeneratePayload())
+	}
+	var decoded EMVQR
+	if err := Unmarshal(got, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(&decoded, c) {
+		t.Errorf("Unmarshal() = %+v, want %+v", decoded, c)
+	}
+}
+
+func TestMarshal_templateMarshaler(t *testing.T) {
+	// MerchantAccountInformation encodes a Value set for a template ID as is
+	c := &EMVQR{}
+	c.SetPayloadFormatIndicator("01")
+	c.AddMerchantAccountInformation("26", &MerchantAccountInformation{Value: "0004ABCD"})
+	want := withCRC("000201" + "26080004ABCD")
+	got, err := Marshal(c)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("Marshal() = %v, want %v", got, want)
+	}
+	if got := c.GeneratePayload(); got != want {
+		t.Errorf("EMVQR.GeneratePayload() = %v, want %v", got, want)
+	}
+}
+
+func TestEMVQR_GeneratePayload_error(t *testing.T) {
+	c := &EMVQR{}
+	c.SetPayloadFormatIndicator("01")
+	c.AddMerchantAccountInformation("7", &MerchantAccountInformation{Value: "X"})
+	if got := c.GeneratePayload(); got != "" {
+		t.Errorf("EMVQR.GeneratePayload() = %v, want \"\"", got)
+	}
+	if _, err := c.MarshalPayload(); err == nil {
+		t.Errorf("EMVQR.MarshalPayload() should fail")
+	}
+
+	c = &EMVQR{}
+	c.SetPayloadFormatIndicator("01")
+	c.RFUforEMVCo = []TLV{{Tag: "10", Length: "01", Value: "X"}}
+	if got := c.GeneratePayload(); got != "" {
+		t.Errorf("EMVQR.GeneratePayload() of RFU for EMVCo 10 = %v, want \"\"", got)
+	}
+	if _, err := c.MarshalPayload(); err == nil {
+		t.Errorf("EMVQR.MarshalPayload() of RFU for EMVCo 10 should fail")
+	}
+	if _, err := c.GeneratePayloadWithLengthPolicy(LengthPolicyBytes); err == nil {
+		t.Errorf("EMVQR.GeneratePayloadWithLengthPolicy() should fail")
+	}
+}
+
+func TestMarshal_errors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{name: "not a struct", v: "000201"},
+		{name: "over max", v: testQR{MerchantName: "BEST TRANSPORT AND LOGISTICS"}},
+		{name: "marshaler error", v: testQR{TransactionAmount: -1}},
+		{name: "invalid ID", v: struct {
+			A string `emv:"1"`
+		}{}},
+		{name: "unknown option", v: struct {
+			A string `emv:"01,omitempty"`
+		}{}},
+		{name: "same ID", v: struct {
+			A string `emv:"01"`
+			B []TLV  `emv:"01-09"`
+		}{}},
+		{name: "range of strings", v: struct {
+			A string `emv:"01-09"`
+		}{}},
+		{name: "unsupported type", v: struct {
+			A int `emv:"01"`
+		}{}},
+		{name: "template of a string", v: struct {
+			A string `emv:"62,template"`
+		}{}},
+		{name: "crc of another ID", v: struct {
+			A string `emv:"62,crc"`
+		}{}},
+		{name: "map key not two digits", v: struct {
+			A map[ID]string `emv:"65-79"`
+		}{A: map[ID]string{"7": "X"}}},
+		{name: "map key out of range", v: struct {
+			A map[ID]string `emv:"65-79"`
+		}{A: map[ID]string{"80": "X"}}},
+		{name: "tag out of range", v: &EMVQR{RFUforEMVCo: []TLV{{Tag: "10", Value: "X"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Marshal(tt.v); err == nil {
+				t.Errorf("Marshal() should fail")
+			}
+		})
+	}
+}
+
+func TestUnmarshal_errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		v       interface{}
+	}{
+		{name: "not a pointer", payload: "000201", v: testQR{}},
+		{name: "nil pointer", payload: "000201", v: (*testQR)(nil)},
+		{name: "over max", payload: "5928BEST TRANSPORT AND LOGISTICS", v: &testQR{}},
+		{name: "unmarshaler error", payload: "5403ABC", v: &testQR{}},
+		{name: "nested over max", payload: "62320528BEST TRANSPORT AND LOGISTICS", v: &testQR{}},
+		{name: "broken payload", payload: "0010", v: &testQR{}},
+		{name: "non-numeric ID", payload: "ab02XY", v: &testQR{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Unmarshal(tt.payload, tt.v); err == nil {
+				t.Errorf("Unmarshal() should fail")
+			}
+		})
+	}
+}
@@ -5,7 +5,7 @@ func Encode(emvqr *EMVQR) (string, error) {
 	if err := emvqr.Validate(); err != nil {
 		return "", err
 	}
-	return emvqr.GeneratePayload(), nil
+	return Marshal(emvqr)
 }
 
 // Decode ...
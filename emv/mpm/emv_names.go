@@ -0,0 +1,94 @@
+package mpm
+
+// idRange names the IDs from start to end.
+type idRange struct {
+	start, end ID
+	name       string
+}
+
+var rootNames = []idRange{
+	{IDPayloadFormatIndicator, IDPayloadFormatIndicator, "Payload Format Indicator"},
+	{IDPointOfInitiationMethod, IDPointOfInitiationMethod, "Point of Initiation Method"},
+	{IDMerchantAccountInformationRangeStart, IDMerchantAccountInformationRangeEnd, "Merchant Account Information"},
+	{IDMerchantCategoryCode, IDMerchantCategoryCode, "Merchant Category Code"},
+	{IDTransactionCurrency, IDTransactionCurrency, "Transaction Currency"},
+	{IDTransactionAmount, IDTransactionAmount, "Transaction Amount"},
+	{IDTipOrConvenienceIndicator, IDTipOrConvenienceIndicator, "Tip or Convenience Indicator"},
+	{IDValueOfConvenienceFeeFixed, IDValueOfConvenienceFeeFixed, "Value of Convenience Fee Fixed"},
+	{IDValueOfConvenienceFeePercentage, IDValueOfConvenienceFeePercentage, "Value of Convenience Fee Percentage"},
+	{IDCountryCode, IDCountryCode, "Country Code"},
+	{IDMerchantName, IDMerchantName, "Merchant Name"},
+	{IDMerchantCity, IDMerchantCity, "Merchant City"},
+	{IDPostalCode, IDPostalCode, "Postal Code"},
+	{IDAdditionalDataFieldTemplate, IDAdditionalDataFieldTemplate, "Additional Data Field Template"},
+	{IDCRC, IDCRC, "CRC"},
+	{IDMerchantInformationLanguageTemplate, IDMerchantInformationLanguageTemplate, "Merchant Information—Language Template"},
+	{IDRFUForEMVCoRangeStart, IDRFUForEMVCoRangeEnd, "RFU for EMVCo"},
+	{IDUnreservedTemplatesRangeStart, IDUnreservedTemplatesRangeEnd, "Unreserved Templates"},
+}
+
+var merchantAccountInformationNames = []idRange{
+	{MerchantAccountInformationIDGloballyUniqueIdentifier, MerchantAccountInformationIDGloballyUniqueIdentifier, "Globally Unique Identifier"},
+	{MerchantAccountInformationIDPaymentNetworkSpecificStart, MerchantAccountInformationIDPaymentNetworkSpecificEnd, "Payment network specific"},
+}
+
+var additionalDataFieldTemplateNames = []idRange{
+	{AdditionalIDBillNumber, AdditionalIDBillNumber, "Bill Number"},
+	{AdditionalIDMobileNumber, AdditionalIDMobileNumber, "Mobile Number"},
+	{AdditionalIDStoreLabel, AdditionalIDStoreLabel, "Store Label"},
+	{AdditionalIDLoyaltyNumber, AdditionalIDLoyaltyNumber, "Loyalty Number"},
+	{AdditionalIDReferenceLabel, AdditionalIDReferenceLabel, "Reference Label"},
+	{AdditionalIDCustomerLabel, AdditionalIDCustomerLabel, "Customer Label"},
+	{AdditionalIDTerminalLabel, AdditionalIDTerminalLabel, "Terminal Label"},
+	{AdditionalIDPurposeTransaction, AdditionalIDPurposeTransaction, "Purpose of Transaction"},
+	{AdditionalIDAdditionalConsumerDataRequest, AdditionalIDAdditionalConsumerDataRequest, "Additional Consumer Data Request"},
+	{AdditionalIDRFUforEMVCoRangeStart, AdditionalIDRFUforEMVCoRangeEnd, "RFU for EMVCo"},
+	{AdditionalIDPaymentSystemSpecificTemplatesRangeStart, AdditionalIDPaymentSystemSpecificTemplatesRangeEnd, "Payment System specific templates"},
+}
+
+var merchantInformationLanguageTemplateNames = []idRange{
+	{MerchantInformationIDLanguagePreference, MerchantInformationIDLanguagePreference, "Language Preference"},
+	{MerchantInformationIDMerchantName, MerchantInformationIDMerchantName, "Merchant Name—Alternate Language"},
+	{MerchantInformationIDMerchantCity, MerchantInformationIDMerchantCity, "Merchant City—Alternate Language"},
+	{MerchantInformationIDRFUforEMVCoRangeStart, MerchantInformationIDRFUforEMVCoRangeEnd, "RFU for EMVCo"},
+}
+
+var unreservedTemplateNames = []idRange{
+	{UnreservedTemplateIDGloballyUniqueIdentifier, UnreservedTemplateIDGloballyUniqueIdentifier, "Globally Unique Identifier"},
+	{UnreservedTemplateIDContextSpecificDataStart, UnreservedTemplateIDContextSpecificDataEnd, "Context Specific Data"},
+}
+
+// IDName returns the EMVCo name of a data object, or "" for IDs with no name.
+// template is the ID of the enclosing template, "" for root data objects.
+func IDName(template, id ID) string {
+	names := rootNames
+	if template != "" {
+		names = templateNames(template)
+	}
+	return lookupName(names, id)
+}
+
+func templateNames(template ID) []idRange {
+	switch {
+	case template == IDAdditionalDataFieldTemplate:
+		return additionalDataFieldTemplateNames
+	case template == IDMerchantInformationLanguageTemplate:
+		return merchantInformationLanguageTemplateNames
+	}
+	if within, err := template.Between(IDMerchantAccountInformationTemplateRangeStart, IDMerchantAccountInformationTemplateRangeEnd); err == nil && within {
+		return merchantAccountInformationNames
+	}
+	if within, err := template.Between(IDUnreservedTemplatesRangeStart, IDUnreservedTemplatesRangeEnd); err == nil && within {
+		return unreservedTemplateNames
+	}
+	return nil
+}
+
+func lookupName(names []idRange, id ID) string {
+	for _, r := range names {
+		if within, err := id.Between(r.start, r.end); err == nil && within {
+			return r.name
+		}
+	}
+	return ""
+}
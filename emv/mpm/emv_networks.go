@@ -0,0 +1,103 @@
+package mpm
+
+import (
+	_ "embed"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// networkEMVCo names the primitive IDs EMVCo reserved for future networks.
+const networkEMVCo = "EMVCo"
+
+//go:embed networks.txt
+var networksTxt string
+
+// primitiveNetworks are the payment networks EMVCo reserved the primitive
+// Merchant Account Information IDs 02-25 for, and guiNetworks maps AID RIDs
+// and reverse domain names to payment networks.
+var primitiveNetworks, guiNetworks = parseNetworks(networksTxt)
+
+func parseNetworks(data string) ([]idRange, map[string]string) {
+	var ids []idRange
+	guis := map[string]string{}
+	for i, line := range strings.Split(data, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		switch {
+		case fields[0] == "id" && len(fields) == 4:
+			ids = append(ids, idRange{ID(fields[1]), ID(fields[2]), fields[3]})
+		case fields[0] == "gui" && len(fields) == 3:
+			guis[strings.ToUpper(fields[1])] = fields[2]
+		default:
+			panic(fmt.Sprintf("mpm: networks.txt:%d: want id, first ID, last ID and network, or gui, GUI and network", i+1))
+		}
+	}
+	return ids, guis
+}
+
+// NetworkName returns the payment network a Globally Unique Identifier
+// belongs to, or "" if it is not known. AIDs are looked up by their RID.
+func NetworkName(gui string) string {
+	gui = strings.ToUpper(gui)
+	if name, ok := guiNetworks[gui]; ok {
+		return name
+	}
+	if len(gui) < 10 {
+		return ""
+	}
+	return guiNetworks[gui[:10]]
+}
+
+// IDNetworkName returns the payment network EMVCo reserved a primitive
+// Merchant Account Information ID (02-25) for, or "" for IDs reserved for
+// EMVCo and other IDs.
+func IDNetworkName(id ID) string {
+	if name := lookupName(primitiveNetworks, id); name != networkEMVCo {
+		return name
+	}
+	return ""
+}
+
+// Network is the payment network of a Merchant Account Information.
+type Network struct {
+	ID   ID
+	Name string // "" if not known
+	// GUI is the Globally Unique Identifier of templates 26-51, "" for 02-25.
+	GUI string
+}
+
+// Networks returns the payment networks of the Merchant Account Information of
+// c in ID order: the network an ID is reserved for in 02-25, the network of
+// the Globally Unique Identifier in 26-51.
+func (c *EMVQR) Networks() []Network {
+	networks := make([]Network, 0, len(c.MerchantAccountInformation))
+	for id, m := range c.MerchantAccountInformation {
+		n := Network{ID: id}
+		if within, err := id.Between(IDMerchantAccountInformationPrimitiveRangeStart, IDMerchantAccountInformationPrimitiveRangeEnd); err == nil && within {
+			n.Name = IDNetworkName(id)
+		} else if m.Value != nil {
+			n.GUI = m.Value.GloballyUniqueIdentifier.Value
+			n.Name = NetworkName(n.GUI)
+		}
+		networks = append(networks, n)
+	}
+	sort.Slice(networks, func(i, j int) bool { return networks[i].ID < networks[j].ID })
+	return networks
+}
+
+// NetworkError reports a primitive Merchant Account Information ID used by
+// another payment network than the one it is reserved for, as told by the
+// brand of its PAN. It is a warning, see NetworkWarnings.
+type NetworkError struct {
+	ID       ID
+	Reserved string // the network the ID is reserved for
+	Network  string // the network of the value
+}
+
+// Error ...
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("MerchantAccountInformation %s is reserved for %s, network: %s", e.ID, e.Reserved, e.Network)
+}
@@ -0,0 +1,67 @@
+package mpm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNetworkName(t *testing.T) {
+	tests := []struct {
+		name string
+		gui  string
+		want string
+	}{
+		{name: "AID", gui: "A0000000031010", want: "Visa"},
+		{name: "lower case AID", gui: "a0000000041010", want: "Mastercard"},
+		{name: "reverse domain name", gui: "sg.paynow", want: "PayNow"},
+		{name: "unknown", gui: "com.example", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NetworkName(tt.gui); got != tt.want {
+				t.Errorf("NetworkName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIDNetworkName(t *testing.T) {
+	tests := []struct {
+		id   ID
+		want string
+	}{
+		{id: "02", want: "Visa"},
+		{id: "05", want: "Mastercard"},
+		{id: "07", want: ""},
+		{id: "16", want: "UnionPay"},
+		{id: "26", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.id), func(t *testing.T) {
+			if got := IDNetworkName(tt.id); got != tt.want {
+				t.Errorf("IDNetworkName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEMVQR_Networks(t *testing.T) {
+	c := &EMVQR{}
+	c.AddMerchantAccountInformation("04", &MerchantAccountInformation{Value: "5555555555554444"})
+	c.AddMerchantAccountInformation("02", &MerchantAccountInformation{Value: "4111111111111111"})
+	c.AddMerchantAccountInformation("20", &MerchantAccountInformation{Value: "12345678"})
+	m := &MerchantAccountInformation{}
+	m.SetGloballyUniqueIdentifier("A000000333010101")
+	c.AddMerchantAccountInformation("26", m)
+	c.AddMerchantAccountInformation("27", &MerchantAccountInformation{})
+	want := []Network{
+		{ID: "02", Name: "Visa"},
+		{ID: "04", Name: "Mastercard"},
+		{ID: "20"},
+		{ID: "26", Name: "UnionPay", GUI: "A000000333010101"},
+		{ID: "27"},
+	}
+	if got := c.Networks(); !reflect.DeepEqual(got, want) {
+		t.Errorf("EMVQR.Networks() = %+v, want %+v", got, want)
+	}
+}
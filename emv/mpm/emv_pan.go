@@ -0,0 +1,66 @@
+package mpm
+
+import (
+	"fmt"
+
+	"github.com/dongri/emv-qrcode/pan"
+)
+
+// validatePANs checks the merchant PANs of the primitive Merchant Account
+// Information (02-25): a PAN of a known brand has to pass pan.Validate.
+// Values that are not 12 to 19 digits, or of no known brand, are not PANs
+// this can check. Only DecodeWithOptions runs it, so that Validate, Encode and
+// Decode keep accepting merchant IDs that merely look like PANs.
+func (c *EMVQR) validatePANs() error {
+	for _, n := range c.Networks() {
+		v, brand := c.merchantPAN(n.ID)
+		if brand == "" {
+			continue
+		}
+		if err := pan.Validate(v); err != nil {
+			return fmt.Errorf("MerchantAccountInformation %s: %s", n.ID, err)
+		}
+	}
+	return nil
+}
+
+// NetworkWarnings returns a *NetworkError for every primitive Merchant Account
+// Information (02-25) holding a PAN whose brand is of another network than
+// the one its ID is reserved for. Such payloads are valid, so Validate does
+// not check this.
+func (c *EMVQR) NetworkWarnings() []*NetworkError {
+	var warnings []*NetworkError
+	for _, n := range c.Networks() {
+		_, brand := c.merchantPAN(n.ID)
+		if brand == "" || n.Name == "" || n.Name == brand.Network() {
+			continue
+		}
+		warnings = append(warnings, &NetworkError{ID: n.ID, Reserved: n.Name, Network: brand.Network()})
+	}
+	return warnings
+}
+
+// merchantPAN returns the value of the primitive Merchant Account Information
+// id and its brand, or "" if the value is not a PAN of a known brand.
+func (c *EMVQR) merchantPAN(id ID) (string, pan.Brand) {
+	if within, err := id.Between(IDMerchantAccountInformationPrimitiveRangeStart, IDMerchantAccountInformationPrimitiveRangeEnd); err != nil || !within {
+		return "", ""
+	}
+	m := c.MerchantAccountInformation[id]
+	if m.Value == nil || !isMerchantPAN(m.Value.Value) {
+		return "", ""
+	}
+	return m.Value.Value, pan.BrandOf(m.Value.Value)
+}
+
+func isMerchantPAN(v string) bool {
+	if len(v) < 12 || len(v) > 19 {
+		return false
+	}
+	for i := 0; i < len(v); i++ {
+		if !isDigit(v[i]) {
+			return false
+		}
+	}
+	return true
+}
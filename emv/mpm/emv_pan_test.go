@@ -0,0 +1,127 @@
+package mpm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestEMVQR_validatePANs(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      ID
+		value   string
+		wantErr bool
+	}{
+		{
+			name:  "Visa PAN in a Visa ID",
+			id:    "02",
+			value: "4111111111111111",
+		},
+		{
+			name:  "Visa PAN in an EMVCo ID",
+			id:    "17",
+			value: "4111111111111111",
+		},
+		{
+			name:  "Diners Club PAN in a Discover ID",
+			id:    "09",
+			value: "36227206271667",
+		},
+		{
+			name:  "merchant ID of no known brand",
+			id:    "04",
+			value: "1234567890123456",
+		},
+		{
+			name:  "not a PAN",
+			id:    "04",
+			value: "4111-1111-1111-1111",
+		},
+		{
+			name:  "Visa PAN in a Mastercard ID",
+			id:    "04",
+			value: "4111111111111111",
+		},
+		{
+			name:    "PAN failing the Luhn check",
+			id:      "02",
+			value:   "4111111111111112",
+			wantErr: true,
+		},
+		{
+			name:    "PAN of a length the brand does not issue",
+			id:      "04",
+			value:   "55555555555544440",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &EMVQR{}
+			c.AddMerchantAccountInformation(tt.id, &MerchantAccountInformation{Value: tt.value})
+			if err := c.validatePANs(); (err != nil) != tt.wantErr {
+				t.Errorf("EMVQR.validatePANs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEMVQR_NetworkWarnings(t *testing.T) {
+	c := &EMVQR{}
+	c.SetPayloadFormatIndicator("01")
+	c.AddMerchantAccountInformation("02", &MerchantAccountInformation{Value: "4111111111111111"})
+	c.AddMerchantAccountInformation("04", &MerchantAccountInformation{Value: "4111111111111111"})
+	c.SetMerchantCategoryCode("4111")
+	c.SetTransactionCurrency("156")
+	c.SetCountryCode("CN")
+	c.SetMerchantName("BEST TRANSPORT")
+	c.SetMerchantCity("BEIJING")
+	want := []*NetworkError{{ID: "04", Reserved: "Mastercard", Network: "Visa"}}
+	if got := c.NetworkWarnings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("EMVQR.NetworkWarnings() = %v, want %v", got, want)
+	}
+
+	payload, err := Encode(c)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	for _, strict := range []bool{false, true} {
+		result, err := DecodeWithOptions(payload, DecodeOptions{Strict: strict})
+		if err != nil {
+			t.Fatalf("DecodeWithOptions(strict: %v) error = %v", strict, err)
+		}
+		var ne *NetworkError
+		if len(result.Warnings) != 1 || !errors.As(result.Warnings[0], &ne) || *ne != *want[0] {
+			t.Errorf("DecodeWithOptions(strict: %v) warnings = %v, want %v", strict, result.Warnings, want)
+		}
+	}
+}
+
+func TestDecodeWithOptions_merchantPAN(t *testing.T) {
+	c := &EMVQR{}
+	c.SetPayloadFormatIndicator("01")
+	c.AddMerchantAccountInformation("02", &MerchantAccountInformation{Value: "4111111111111112"})
+	c.SetMerchantCategoryCode("4111")
+	c.SetTransactionCurrency("156")
+	c.SetCountryCode("CN")
+	c.SetMerchantName("BEST TRANSPORT")
+	c.SetMerchantCity("BEIJING")
+	payload, err := Encode(c)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if _, err := Decode(payload); err != nil {
+		t.Errorf("Decode() error = %v", err)
+	}
+	if _, err := DecodeWithOptions(payload, DecodeOptions{Strict: true}); err == nil {
+		t.Error("DecodeWithOptions(strict) error = nil, want an error")
+	}
+	result, err := DecodeWithOptions(payload, DecodeOptions{})
+	if err != nil {
+		t.Fatalf("DecodeWithOptions() error = %v", err)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("DecodeWithOptions() warnings = %v, want the merchant PAN error", result.Warnings)
+	}
+}
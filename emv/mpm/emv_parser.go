@@ -4,17 +4,124 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"unicode/utf8"
+
+	"github.com/dongri/emv-qrcode/redact"
 )
 
 // ParserError ...
+// Path, Offset, Declared, Available and Payload are set when the error is located
+// in a payload: Path is the ID path of the failing data object (e.g. 62 > 05),
+// Offset its position in the payload in characters and ByteOffset in bytes, which
+// differ after multibyte characters such as in the Language Template (64),
+// Declared the declared value length and Available the characters left in the
+// enclosing template.
+// PANs in the message and in the payload rendered by Caret are redacted with redact.Default.
 type ParserError struct {
-	Func string
-	Err  error
+	Func       string
+	Err        error
+	Path       []ID
+	Offset     int64
+	ByteOffset int64
+	Declared   int64
+	Available  int64
+	Payload    string
 }
 
 func (e *ParserError) Error() string {
-	return "parser." + e.Func + ": " + e.Err.Error()
+	s := "parser." + e.Func + ": "
+	if len(e.Path) > 0 {
+		s += formatPath(e.Path) + ": "
+	}
+	s += redact.Default.Text(e.Err.Error())
+	if e.Payload != "" {
+		s += fmt.Sprintf(" (offset: %d", e.Offset)
+		if e.ByteOffset != e.Offset {
+			s += fmt.Sprintf(", byte offset: %d", e.ByteOffset)
+		}
+		if e.Declared > e.Available {
+			s += fmt.Sprintf(", declared: %d, available: %d", e.Declared, e.Available)
+		}
+		s += ")"
+	}
+	return s
+}
+
+// Caret renders the payload with a caret under the failing data object, followed by the error.
+func (e *ParserError) Caret() string {
+	return e.CaretWith(redact.Default)
+}
+
+// CaretWith is Caret with the PANs of the payload redacted by r.
+func (e *ParserError) CaretWith(r redact.Redactor) string {
+	if e.Payload == "" {
+		return e.Error()
+	}
+	payload, offset := redactPayload(r, e.Payload, int(e.Offset))
+	return payload + "\n" + strings.Repeat(" ", offset) + "^\n" + e.Error()
+}
+
+// byteOffset returns the byte offset of the character at offset in payload.
+func byteOffset(payload string, offset int64) int64 {
+	var n int64
+	for i := range payload {
+		if n == offset {
+			return int64(i)
+		}
+		n++
+	}
+	return int64(len(payload))
+}
+
+func formatPath(path []ID) string {
+	s := make([]string, len(path))
+	for i, id := range path {
+		s[i] = id.String()
+	}
+	return strings.Join(s, " > ")
+}
+
+func duplicateIDError(fn string, id ID) *ParserError {
+	return &ParserError{
+		Func: fn,
+		Err:  fmt.Errorf("duplicate ID %s", id),
+	}
+}
+
+func unknownIDError(fn string, id ID) *ParserError {
+	return &ParserError{
+		Func: fn,
+		Err:  fmt.Errorf("unknown ID %s", id),
+	}
+}
+
+func orderError(fn string, id ID) *ParserError {
+	return &ParserError{
+		Func: fn,
+		Err:  fmt.Errorf("Payload Format Indicator (%s) should be the first data object, ID: %s", IDPayloadFormatIndicator, id),
+	}
+}
+
+func trailingDataError(fn string) *ParserError {
+	return &ParserError{
+		Func: fn,
+		Err:  fmt.Errorf("trailing data after CRC (%s)", IDCRC),
+	}
+}
+
+func missingCRCError(fn string) *ParserError {
+	return &ParserError{
+		Func: fn,
+		Err:  fmt.Errorf("CRC (%s) is missing", IDCRC),
+	}
+}
+
+func crcMismatchError(fn, got, want string) *ParserError {
+	return &ParserError{
+		Func: fn,
+		Err:  fmt.Errorf("CRC mismatch. got: %s, want: %s", got, want),
+	}
 }
 
 func notCallError(fn string) *ParserError {
@@ -38,12 +145,12 @@ func syntaxError(fn, str string) *ParserError {
 	}
 }
 
-// func idRangeError(fn string, id ID) *ParserError {
-// 	return &ParserError{
-// 		Func: fn,
-// 		Err:  errors.New("id range invalid. id: " + id.String()),
-// 	}
-// }
+func splitCharacterError(fn string, start, length int64) *ParserError {
+	return &ParserError{
+		Func: fn,
+		Err:  fmt.Errorf("value length splits a character. start: %d, length: %d", start, length),
+	}
+}
 
 // const ...
 const (
@@ -57,6 +164,14 @@ type Parser struct {
 	max     int64
 	source  []rune
 	err     error
+	policy  LengthPolicy
+	offsets []int64 // byte offset of each rune in source, only for LengthPolicyBytes
+	text    string  // source as given, values are sliced from it when offsets is set
+	base    int64   // offset of source in the root payload
+	path    []ID    // IDs of the templates source is nested in
+	root    string  // root payload
+	decode  *decodeState
+	seen    map[ID]bool // IDs seen in source, only when decode is set
 }
 
 // NewParser ...
@@ -66,7 +181,57 @@ func NewParser(payload string) *Parser {
 		max:     int64(utf8.RuneCountInString(payload)),
 		source:  []rune(payload),
 		err:     nil,
+		root:    payload,
+	}
+}
+
+// child returns a parser over value, the value of the current data object.
+func (p *Parser) child(value string) *Parser {
+	c := NewParserWithLengthPolicy(value, p.policy)
+	c.base = p.base + p.current + IDWordCount + ValueLengthWordCount
+	c.path = append(append([]ID(nil), p.path...), p.currentID())
+	c.root = p.root
+	c.decode = p.decode
+	return c
+}
+
+// currentID returns the ID of the current data object without recording an error.
+func (p *Parser) currentID() ID {
+	if p.current < 0 || p.max < p.current+IDWordCount {
+		return ID("")
+	}
+	return ID(string(p.source[p.current : p.current+IDWordCount]))
+}
+
+// locate records where in the root payload e happened.
+func (p *Parser) locate(e *ParserError, declared, available int64) *ParserError {
+	e.Path = append([]ID(nil), p.path...)
+	if id := p.currentID(); id != "" {
+		e.Path = append(e.Path, id)
 	}
+	e.Offset = p.base + p.current
+	e.ByteOffset = byteOffset(p.root, e.Offset)
+	e.Declared = declared
+	e.Available = available
+	e.Payload = p.root
+	return e
+}
+
+// templateError returns err located at the start of the template p parses.
+func (p *Parser) templateError(fn string, err error) *ParserError {
+	return &ParserError{
+		Func:       fn,
+		Err:        err,
+		Path:       append([]ID(nil), p.path...),
+		Offset:     p.base,
+		ByteOffset: byteOffset(p.root, p.base),
+		Payload:    p.root,
+	}
+}
+
+// invalidIDError returns the error for a data object whose ID is not a number.
+func (p *Parser) invalidIDError() error {
+	return p.locate(syntaxError("ID", p.currentID().String()), 0, 0)
 }
 
 // Next ...
@@ -74,19 +239,34 @@ func (p *Parser) Next() bool {
 	if p.err != nil {
 		return false
 	}
-	if p.current < 0 {
-		p.current = 0
-	} else {
-		valueLength := p.ValueLength()
-		if p.err != nil {
+	for {
+		if p.current < 0 {
+			p.current = 0
+		} else {
+			valueLength := p.ValueLength()
+			if p.err != nil {
+				return false
+			}
+			end, err := p.valueEnd(p.current+IDWordCount+ValueLengthWordCount, valueLength)
+			if err != nil {
+				p.err = err
+				return false
+			}
+			p.current = end
+		}
+		if p.current >= p.max {
+			return false
+		}
+		if p.decode == nil {
+			return true
+		}
+		switch p.inspect() {
+		case inspectKeep:
+			return true
+		case inspectStop:
 			return false
 		}
-		p.current += valueLength + IDWordCount + ValueLengthWordCount
-	}
-	if p.current >= p.max {
-		return false
 	}
-	return true
 }
 
 // ID ...
@@ -99,7 +279,7 @@ func (p *Parser) ID() ID {
 		return ID("")
 	}
 	if p.max < end {
-		p.err = outOfRangeError(fnID, p.current, p.max, start, end)
+		p.err = p.locate(outOfRangeError(fnID, p.current, p.max, start, end), IDWordCount+ValueLengthWordCount, p.max-p.current)
 		return ID("")
 	}
 	id := ID(string(p.source[start:end]))
@@ -116,31 +296,46 @@ func (p *Parser) ValueLength() int64 {
 		return 0
 	}
 	if p.max < end {
-		p.err = outOfRangeError(fnValueLength, p.current, p.max, start, end)
+		p.err = p.locate(outOfRangeError(fnValueLength, p.current, p.max, start, end), IDWordCount+ValueLengthWordCount, p.max-p.current)
 		return 0
 	}
-	strValueLength := string(p.source[start:end])
-	len, err := strconv.ParseInt(strValueLength, 10, 64)
-	if err != nil {
-		p.err = syntaxError(fnValueLength, strValueLength)
-		return 0
+	// exactly two digits: strconv would also accept signs such as "-1" or "+1"
+	var length int64
+	for _, r := range p.source[start:end] {
+		if r < '0' || r > '9' {
+			p.err = p.locate(syntaxError(fnValueLength, string(p.source[start:end])), 0, 0)
+			return 0
+		}
+		length = length*10 + int64(r-'0')
 	}
-	return len
+	return length
 }
 
 // Value ...
 func (p *Parser) Value() string {
 	const fnValue = "Value"
 	start := p.current + IDWordCount + ValueLengthWordCount
-	end := start + p.ValueLength()
 	if p.current < 0 {
 		p.err = notCallError(fnValue)
 		return ""
 	}
+	valueLength := p.ValueLength()
+	if p.err != nil {
+		return ""
+	}
+	end, err := p.valueEnd(start, valueLength)
+	if err != nil {
+		p.err = err
+		return ""
+	}
 	if p.max < end {
-		p.err = outOfRangeError(fnValue, p.current, p.max, start, end)
+		p.err = p.locate(outOfRangeError(fnValue, p.current, p.max, start, end), valueLength, p.max-start)
 		return ""
 	}
+	if p.offsets != nil {
+		// string(source) would replace invalid UTF-8 and change the byte lengths
+		return p.text[p.offsets[start]:p.offsets[end]]
+	}
 	return string(p.source[start:end])
 }
 
@@ -148,3 +343,23 @@ func (p *Parser) Value() string {
 func (p *Parser) Err() error {
 	return p.err
 }
+
+// valueEnd returns the index in source where a value of the given length starting at start ends.
+func (p *Parser) valueEnd(start, length int64) (int64, error) {
+	if p.policy != LengthPolicyBytes || start >= p.max {
+		return start + length, nil
+	}
+	target := p.offsets[start] + length
+	if target > p.offsets[p.max] {
+		// out of range, the rune count beyond the source does not matter
+		return p.max + 1, nil
+	}
+	end := start
+	for p.offsets[end] < target {
+		end++
+	}
+	if p.offsets[end] != target {
+		return 0, p.locate(splitCharacterError("valueEnd", start, length), length, p.offsets[p.max]-p.offsets[start])
+	}
+	return end, nil
+}
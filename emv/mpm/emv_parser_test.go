@@ -39,6 +39,91 @@ func TestParserError_Error(t *testing.T) {
 	}
 }
 
+func TestParserError_Location(t *testing.T) {
+	tests := []struct {
+		name      string
+		payload   string
+		wantPath  []ID
+		wantError string
+		wantCaret string
+	}{
+		{
+			name:      "nested value out of range",
+			payload:   "0002016210050201000599",
+			wantPath:  []ID{"62", "00"},
+			wantError: "parser.Value: 62 > 00: bounds out of range. current: 6, max: 10, start: 10, end: 15 (offset: 16, declared: 5, available: 0)",
+			wantCaret: "0002016210050201000599\n                ^\n",
+		},
+		{
+			name:      "template value out of range",
+			payload:   "00020162060599",
+			wantPath:  []ID{"62"},
+			wantError: "parser.Value: 62: bounds out of range. current: 6, max: 14, start: 10, end: 16 (offset: 6, declared: 6, available: 4)",
+			wantCaret: "00020162060599\n      ^\n",
+		},
+		{
+			name:      "nested value length is not number",
+			payload:   "000201620405ab",
+			wantPath:  []ID{"62", "05"},
+			wantError: "parser.ValueLength: 62 > 05: parsing \"ab\": invalid syntax (offset: 10)",
+			wantCaret: "000201620405ab\n          ^\n",
+		},
+		{
+			name:      "truncated data object",
+			payload:   "00020162030501",
+			wantPath:  []ID{"62", "05"},
+			wantError: "parser.ValueLength: 62 > 05: bounds out of range. current: 0, max: 3, start: 2, end: 4 (offset: 10, declared: 4, available: 3)",
+			wantCaret: "00020162030501\n          ^\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseEMVQR(tt.payload)
+			e, ok := err.(*ParserError)
+			if !ok {
+				t.Fatalf("ParseEMVQR() error = %v, want *ParserError", err)
+			}
+			if !reflect.DeepEqual(e.Path, tt.wantPath) {
+				t.Errorf("ParserError.Path = %v, want %v", e.Path, tt.wantPath)
+			}
+			if got := e.Error(); got != tt.wantError {
+				t.Errorf("ParserError.Error() = %v, want %v", got, tt.wantError)
+			}
+			if got := e.Caret(); got != tt.wantCaret+tt.wantError {
+				t.Errorf("ParserError.Caret() = %v, want %v", got, tt.wantCaret+tt.wantError)
+			}
+			_, err = ParseEMVQRBytes([]byte(tt.payload))
+			b, ok := err.(*ParserError)
+			if !ok {
+				t.Fatalf("ParseEMVQRBytes() error = %v, want *ParserError", err)
+			}
+			if !reflect.DeepEqual(b.Path, e.Path) || b.Offset != e.Offset || b.ByteOffset != e.ByteOffset || b.Declared != e.Declared || b.Available != e.Available {
+				t.Errorf("ParseEMVQRBytes() error = %v, want %v", b, e)
+			}
+		})
+	}
+}
+
+func TestParserError_ByteOffset(t *testing.T) {
+	// 北京 is 2 characters and 6 bytes
+	payload := "000201" + "64120002ZH0102北京" + "62060599"
+	for name, parse := range map[string]func(string) error{
+		"ParseEMVQR":      func(s string) error { _, err := ParseEMVQR(s); return err },
+		"ParseEMVQRBytes": func(s string) error { _, err := ParseEMVQRBytes([]byte(s)); return err },
+	} {
+		e, ok := parse(payload).(*ParserError)
+		if !ok {
+			t.Fatalf("%s() error = %v, want *ParserError", name, e)
+		}
+		if e.Offset != 22 || e.ByteOffset != 26 {
+			t.Errorf("%s() error offset = %d, byte offset = %d, want 22, 26", name, e.Offset, e.ByteOffset)
+		}
+		if got := payload[e.ByteOffset:]; got != "62060599" {
+			t.Errorf("%s() error at %q, want %q", name, got, "62060599")
+		}
+	}
+}
+
 func TestNewParser(t *testing.T) {
 	type args struct {
 		payload string
@@ -0,0 +1,170 @@
+package mpm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// ConsumerPrompt is the value of 62.01-62.08 meaning that the mobile application
+// has to prompt the consumer for the value.
+const ConsumerPrompt = "***"
+
+// Length limits of the consumer-entered values and of the Additional Data Field
+// Template (ID "62") they are filled into
+const (
+	ConsumerPromptValueMaxLength         = 25
+	AdditionalDataFieldTemplateMaxLength = 99
+)
+
+// promptable returns the data objects 62.01-62.08 in ID order.
+func (s *AdditionalDataFieldTemplate) promptable() []*TLV {
+	return []*TLV{
+		&s.BillNumber,
+		&s.MobileNumber,
+		&s.StoreLabel,
+		&s.LoyaltyNumber,
+		&s.ReferenceLabel,
+		&s.CustomerLabel,
+		&s.TerminalLabel,
+		&s.PurposeTransaction,
+	}
+}
+
+// PromptIDs returns the IDs whose value is "***" and has to be entered by the consumer.
+func (s *AdditionalDataFieldTemplate) PromptIDs() []ID {
+	if s == nil {
+		return nil
+	}
+	var ids []ID
+	for _, tlv := range s.promptable() {
+		if tlv.Value == ConsumerPrompt {
+			ids = append(ids, tlv.Tag)
+		}
+	}
+	return ids
+}
+
+// Complete returns a copy of the template where every "***" value is replaced
+// with the consumer-entered value for its ID.
+func (s *AdditionalDataFieldTemplate) Complete(values map[ID]string) (*AdditionalDataFieldTemplate, error) {
+	if s == nil {
+		if len(values) > 0 {
+			return nil, fmt.Errorf("AdditionalDataFieldTemplate does not request any consumer input")
+		}
+		return nil, nil
+	}
+	if err := checkPromptValues(s.PromptIDs(), values); err != nil {
+		return nil, err
+	}
+	t := *s
+	t.RFUforEMVCo = append([]TLV(nil), s.RFUforEMVCo...)
+	t.PaymentSystemSpecific = append([]TLV(nil), s.PaymentSystemSpecific...)
+	for _, tlv := range t.promptable() {
+		if v, ok := values[tlv.Tag]; ok {
+			*tlv = TLV{Tag: tlv.Tag, Length: l(v), Value: v}
+		}
+	}
+	v, err := marshalTemplate(reflect.ValueOf(&t).Elem(), false)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAdditionalDataFieldTemplateLength(v); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// PromptIDs returns the Additional Data Field Template IDs the consumer has to enter.
+func (c *EMVQR) PromptIDs() []ID {
+	return c.AdditionalDataFieldTemplate.PromptIDs()
+}
+
+// Complete returns a copy of the EMVQR with the consumer-entered values filled in.
+func (c *EMVQR) Complete(values map[ID]string) (*EMVQR, error) {
+	t, err := c.AdditionalDataFieldTemplate.Complete(values)
+	if err != nil {
+		return nil, err
+	}
+	emvqr := *c
+	emvqr.AdditionalDataFieldTemplate = t
+	return &emvqr, nil
+}
+
+// CompletePayload fills the consumer-entered values into a payload. Object order
+// and unknown IDs are kept as they are and the CRC (63) is recomputed.
+func CompletePayload(payload string, values map[ID]string) (string, error) {
+	objects, err := parseDataObjects(payload)
+	if err != nil {
+		return "", err
+	}
+	var prompts []ID
+	for i, o := range objects {
+		if o.ID != IDAdditionalDataFieldTemplate {
+			continue
+		}
+		p := NewParser(o.Value)
+		var sb strings.Builder
+		for p.Next() {
+			id := p.ID()
+			value := p.Value()
+			if p.Err() != nil {
+				break
+			}
+			if value == ConsumerPrompt && isPromptable(id) {
+				prompts = append(prompts, id)
+				if v, ok := values[id]; ok {
+					value = v
+				}
+			}
+			sb.WriteString(format(id, value))
+		}
+		if err := p.Err(); err != nil {
+			return "", err
+		}
+		if err := checkAdditionalDataFieldTemplateLength(sb.String()); err != nil {
+			return "", err
+		}
+		objects[i].Value = sb.String()
+	}
+	if err := checkPromptValues(prompts, values); err != nil {
+		return "", err
+	}
+	return formatDataObjects(objects)
+}
+
+func isPromptable(id ID) bool {
+	within, err := id.Between(AdditionalIDBillNumber, AdditionalIDPurposeTransaction)
+	return err == nil && within
+}
+
+func checkPromptValues(prompts []ID, values map[ID]string) error {
+	requested := make(map[ID]bool, len(prompts))
+	for _, id := range prompts {
+		requested[id] = true
+		v, ok := values[id]
+		if !ok || v == "" {
+			return fmt.Errorf("value of %s is required by consumer prompt", id)
+		}
+		if v == ConsumerPrompt {
+			return fmt.Errorf("value of %s should not be %q", id, ConsumerPrompt)
+		}
+		if utf8.RuneCountInString(v) > ConsumerPromptValueMaxLength {
+			return fmt.Errorf("value of %s should be at most %d characters, value: %s", id, ConsumerPromptValueMaxLength, v)
+		}
+	}
+	for id := range values {
+		if !requested[id] {
+			return fmt.Errorf("value of %s is not requested by consumer prompt", id)
+		}
+	}
+	return nil
+}
+
+func checkAdditionalDataFieldTemplateLength(value string) error {
+	if n := utf8.RuneCountInString(value); n > AdditionalDataFieldTemplateMaxLength {
+		return fmt.Errorf("AdditionalDataFieldTemplate should be at most %d characters, length: %d", AdditionalDataFieldTemplateMaxLength, n)
+	}
+	return nil
+}
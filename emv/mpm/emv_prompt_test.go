@@ -0,0 +1,130 @@
+package mpm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAdditionalDataFieldTemplate_PromptIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		template *AdditionalDataFieldTemplate
+		want     []ID
+	}{
+		{
+			name:     "nil",
+			template: nil,
+			want:     nil,
+		},
+		{
+			name: "customer label and mobile number",
+			template: &AdditionalDataFieldTemplate{
+				MobileNumber:  TLV{Tag: AdditionalIDMobileNumber, Length: "03", Value: "***"},
+				StoreLabel:    TLV{Tag: AdditionalIDStoreLabel, Length: "04", Value: "1234"},
+				CustomerLabel: TLV{Tag: AdditionalIDCustomerLabel, Length: "03", Value: "***"},
+			},
+			want: []ID{AdditionalIDMobileNumber, AdditionalIDCustomerLabel},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.template.PromptIDs(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AdditionalDataFieldTemplate.PromptIDs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEMVQR_Complete(t *testing.T) {
+	emvqr := &EMVQR{
+		AdditionalDataFieldTemplate: &AdditionalDataFieldTemplate{
+			StoreLabel:    TLV{Tag: AdditionalIDStoreLabel, Length: "04", Value: "1234"},
+			CustomerLabel: TLV{Tag: AdditionalIDCustomerLabel, Length: "03", Value: "***"},
+		},
+	}
+	tests := []struct {
+		name    string
+		values  map[ID]string
+		want    *AdditionalDataFieldTemplate
+		wantErr bool
+	}{
+		{
+			name:   "ok",
+			values: map[ID]string{AdditionalIDCustomerLabel: "DONGRI"},
+			want: &AdditionalDataFieldTemplate{
+				StoreLabel:    TLV{Tag: AdditionalIDStoreLabel, Length: "04", Value: "1234"},
+				CustomerLabel: TLV{Tag: AdditionalIDCustomerLabel, Length: "06", Value: "DONGRI"},
+			},
+		},
+		{
+			name:    "lack of value",
+			values:  map[ID]string{},
+			wantErr: true,
+		},
+		{
+			name:    "not requested",
+			values:  map[ID]string{AdditionalIDCustomerLabel: "DONGRI", AdditionalIDStoreLabel: "5678"},
+			wantErr: true,
+		},
+		{
+			name:    "too long value",
+			values:  map[ID]string{AdditionalIDCustomerLabel: strings.Repeat("A", 26)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := emvqr.Complete(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("EMVQR.Complete() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got.AdditionalDataFieldTemplate, tt.want) {
+				t.Errorf("EMVQR.Complete() = %v, want %v", got.AdditionalDataFieldTemplate, tt.want)
+			}
+			if emvqr.AdditionalDataFieldTemplate.CustomerLabel.Value != ConsumerPrompt {
+				t.Errorf("EMVQR.Complete() modified the original template")
+			}
+		})
+	}
+}
+
+func TestCompletePayload(t *testing.T) {
+	payload := "000201" + "62260603***0708A60086670203***"
+	values := map[ID]string{
+		AdditionalIDCustomerLabel: "DONGRI",
+		AdditionalIDMobileNumber:  "0900000000",
+	}
+	want := "000201" + "62360606DONGRI0708A600866702100900000000"
+	want += formatCrc(want)
+	got, err := CompletePayload(payload, values)
+	if err != nil {
+		t.Fatalf("CompletePayload() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("CompletePayload() = %v, want %v", got, want)
+	}
+	if _, err := CompletePayload(payload, map[ID]string{AdditionalIDCustomerLabel: "DONGRI"}); err == nil {
+		t.Errorf("CompletePayload() error = %v, wantErr %v", err, true)
+	}
+	if _, err := CompletePayload(payload, map[ID]string{
+		AdditionalIDCustomerLabel: strings.Repeat("A", 26),
+		AdditionalIDMobileNumber:  "0900000000",
+	}); err == nil {
+		t.Errorf("CompletePayload() error = %v, wantErr %v", err, true)
+	}
+	// every value fits, but the template does not
+	long := "000201" + "62280103***0203***0303***0403***"
+	if _, err := CompletePayload(long, map[ID]string{
+		AdditionalIDBillNumber:    strings.Repeat("1", 25),
+		AdditionalIDMobileNumber:  strings.Repeat("2", 25),
+		AdditionalIDStoreLabel:    strings.Repeat("3", 25),
+		AdditionalIDLoyaltyNumber: strings.Repeat("4", 25),
+	}); err == nil {
+		t.Errorf("CompletePayload() error = %v, wantErr %v", err, true)
+	}
+}
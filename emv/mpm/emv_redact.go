@@ -0,0 +1,165 @@
+package mpm
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dongri/emv-qrcode/redact"
+)
+
+// PrintRedactor redacts the PANs printed by BinaryData, RawData and JSON.
+// Set it to redact.Redactor{} to print values as is.
+var PrintRedactor = redact.Default
+
+// Redact returns a copy of c with the PANs found in its values redacted by r,
+// such as PANs in payment network specific Merchant Account Information. The
+// copy is for printing: its CRC is the CRC of c. The Extension of an unreserved
+// template is redacted as it is now, as MarshalEMV would encode it.
+func (c *EMVQR) Redact(r redact.Redactor) *EMVQR {
+	d := *c
+	if c.MerchantAccountInformation != nil {
+		d.MerchantAccountInformation = make(map[ID]MerchantAccountInformationTLV, len(c.MerchantAccountInformation))
+		for id, m := range c.MerchantAccountInformation {
+			if m.Value != nil {
+				v := *m.Value
+				v.Value = r.Text(v.Value)
+				v.GloballyUniqueIdentifier = redactTLV(r, v.GloballyUniqueIdentifier)
+				v.PaymentNetworkSpecific = redactTLVs(r, v.PaymentNetworkSpecific)
+				m.Value = &v
+			}
+			d.MerchantAccountInformation[id] = m
+		}
+	}
+	d.MerchantName = redactTLV(r, c.MerchantName)
+	d.MerchantCity = redactTLV(r, c.MerchantCity)
+	d.PostalCode = redactTLV(r, c.PostalCode)
+	if c.AdditionalDataFieldTemplate != nil {
+		a := *c.AdditionalDataFieldTemplate
+		for _, tlv := range []*TLV{&a.BillNumber, &a.MobileNumber, &a.StoreLabel, &a.LoyaltyNumber, &a.ReferenceLabel, &a.CustomerLabel, &a.TerminalLabel, &a.PurposeTransaction, &a.AdditionalConsumerDataRequest} {
+			*tlv = redactTLV(r, *tlv)
+		}
+		a.RFUforEMVCo = redactTLVs(r, a.RFUforEMVCo)
+		a.PaymentSystemSpecific = redactTLVs(r, a.PaymentSystemSpecific)
+		d.AdditionalDataFieldTemplate = &a
+	}
+	d.RFUforEMVCo = redactTLVs(r, c.RFUforEMVCo)
+	if c.UnreservedTemplates != nil {
+		d.UnreservedTemplates = make(map[ID]UnreservedTemplateTLV, len(c.UnreservedTemplates))
+		for id, u := range c.UnreservedTemplates {
+			if u.Value != nil {
+				v := *u.Value
+				if v.Extension != nil {
+					// redact the data of the Extension as it is now and
+					// decode the redacted Extension from it
+					if t, err := v.synced(); err == nil {
+						u.Length = l(t.String())
+						v = *t
+					}
+					v.Extension = nil
+				}
+				v.GloballyUniqueIdentifier = redactTLV(r, v.GloballyUniqueIdentifier)
+				v.ContextSpecificData = redactTLVs(r, v.ContextSpecificData)
+				if u.Value.Extension != nil {
+					// redacted data the type does not accept leaves it nil
+					_ = v.decodeExtension()
+				}
+				u.Value = &v
+			}
+			d.UnreservedTemplates[id] = u
+		}
+	}
+	return &d
+}
+
+func redactTLV(r redact.Redactor, tlv TLV) TLV {
+	if tlv.Value == "" {
+		return tlv
+	}
+	if v := r.Text(tlv.Value); v != tlv.Value {
+		tlv.Value = v
+		tlv.Length = l(v)
+	}
+	return tlv
+}
+
+func redactTLVs(r redact.Redactor, tlvs []TLV) []TLV {
+	if tlvs == nil {
+		return nil
+	}
+	d := make([]TLV, len(tlvs))
+	for i, tlv := range tlvs {
+		d[i] = redactTLV(r, tlv)
+	}
+	return d
+}
+
+// payloadRedactor redacts the values of the data objects of a payload, which
+// may be broken, and follows where an offset in the payload moves to.
+type payloadRedactor struct {
+	r       redact.Redactor
+	offset  int
+	moved   int
+	found   bool
+	written int
+	sb      strings.Builder
+}
+
+// redactPayload returns payload with the PANs in its values redacted by r, and
+// the offset in the result of offset, in characters.
+func redactPayload(r redact.Redactor, payload string, offset int) (string, int) {
+	p := &payloadRedactor{r: r, offset: offset}
+	runes := []rune(payload)
+	p.dataObjects(runes, 0, false)
+	if !p.found {
+		p.moved = p.written
+	}
+	return p.sb.String(), p.moved
+}
+
+func (p *payloadRedactor) dataObjects(s []rune, at int, nested bool) {
+	for i := 0; i < len(s); {
+		if i+4 > len(s) || !isNumericID(ID(s[i:i+2])) || !isNumericID(ID(s[i+2:i+4])) {
+			p.text(s[i:], at+i)
+			return
+		}
+		id := ID(s[i : i+2])
+		n, _ := strconv.Atoi(string(s[i+2 : i+4]))
+		p.plain(s[i:i+4], at+i)
+		i += 4
+		if n > len(s)-i {
+			// broken data object, the value runs to the end
+			n = len(s) - i
+		}
+		value := s[i : i+n]
+		if !nested && isTemplate(id) {
+			p.dataObjects(value, at+i, true)
+		} else {
+			p.text(value, at+i)
+		}
+		i += n
+	}
+}
+
+func (p *payloadRedactor) plain(s []rune, at int) {
+	if !p.found && p.offset >= at && p.offset < at+len(s) {
+		p.moved = p.written + p.offset - at
+		p.found = true
+	}
+	p.sb.WriteString(string(s))
+	p.written += len(s)
+}
+
+func (p *payloadRedactor) text(s []rune, at int) {
+	redacted := p.r.Text(string(s))
+	if redacted == string(s) {
+		p.plain(s, at)
+		return
+	}
+	if !p.found && p.offset >= at && p.offset < at+len(s) {
+		p.moved = p.written
+		p.found = true
+	}
+	p.sb.WriteString(redacted)
+	p.written += utf8.RuneCountInString(redacted)
+}
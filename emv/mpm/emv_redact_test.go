@@ -0,0 +1,93 @@
+package mpm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dongri/emv-qrcode/redact"
+)
+
+func TestEMVQR_Redact(t *testing.T) {
+	emvqr := new(EMVQR)
+	emvqr.SetPayloadFormatIndicator("01")
+	emvqr.AddMerchantAccountInformation(ID("02"), &MerchantAccountInformation{Value: "4111111111111111"})
+	mai := new(MerchantAccountInformation)
+	mai.SetGloballyUniqueIdentifier("A0000000031010")
+	mai.AddPaymentNetworkSpecific("01", "PAN5555555555554444")
+	emvqr.AddMerchantAccountInformation(ID("26"), mai)
+	emvqr.SetMerchantCategoryCode("4111")
+	emvqr.SetTransactionCurrency("156")
+	emvqr.SetCountryCode("CN")
+	emvqr.SetMerchantName("BEST TRANSPORT")
+	emvqr.SetMerchantCity("BEIJING")
+	additional := new(AdditionalDataFieldTemplate)
+	additional.SetBillNumber("378282246310005")
+	emvqr.SetAdditionalDataFieldTemplate(additional)
+	payload := emvqr.GeneratePayload()
+
+	tests := []struct {
+		name     string
+		redactor redact.Redactor
+		want     []string
+	}{
+		{
+			name:     "first 6 last 4",
+			redactor: redact.Default,
+			want:     []string{"02 16 411111******1111", "01 19 PAN555555******4444", "01 15 378282*****0005"},
+		},
+		{
+			name:     "hash",
+			redactor: redact.Redactor{Policy: redact.Hash, Key: []byte("k")},
+			want:     []string{"02 16 " + redact.Redactor{Policy: redact.Hash, Key: []byte("k")}.PAN("4111111111111111")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redacted := emvqr.Redact(tt.redactor)
+			for _, out := range []string{redacted.RawData(), redacted.JSON(), redacted.BinaryData()} {
+				if strings.Contains(out, "4111111111111111") || strings.Contains(out, "34313131313131313131313131313131") {
+					t.Errorf("EMVQR.Redact() output has the PAN in clear: %s", out)
+				}
+			}
+			raw := redacted.RawData()
+			for _, want := range tt.want {
+				if !strings.Contains(raw, want) {
+					t.Errorf("EMVQR.Redact().RawData() = %s, want %s", raw, want)
+				}
+			}
+		})
+	}
+	if got := emvqr.GeneratePayload(); got != payload {
+		t.Errorf("EMVQR.Redact() changed the original: %s, want %s", got, payload)
+	}
+	for _, out := range []string{emvqr.RawData(), emvqr.JSON(), emvqr.BinaryData()} {
+		if strings.Contains(out, "4111111111111111") || strings.Contains(out, "34313131313131313131313131313131") {
+			t.Errorf("EMVQR output has the PAN in clear by default: %s", out)
+		}
+	}
+	PrintRedactor = redact.Redactor{}
+	defer func() { PrintRedactor = redact.Default }()
+	if raw := emvqr.RawData(); !strings.Contains(raw, "02 16 4111111111111111") {
+		t.Errorf("EMVQR.RawData() = %s, want the PAN in clear without PrintRedactor", raw)
+	}
+}
+
+func TestParserError_Redact(t *testing.T) {
+	// 02 declares 20 characters, more than are left
+	_, err := ParseEMVQR("00020102204111111111111111")
+	e, ok := err.(*ParserError)
+	if !ok {
+		t.Fatalf("ParseEMVQR() error = %v, want *ParserError", err)
+	}
+	if got := e.Caret(); strings.Contains(got, "4111111111111111") || !strings.HasPrefix(got, "0002010220411111******1111\n      ^\n") {
+		t.Errorf("ParserError.Caret() = %s", got)
+	}
+	hash := redact.Redactor{Policy: redact.Hash}
+	want := "00020102" + "20" + hash.PAN("4111111111111111") + "\n      ^\n"
+	if got := e.CaretWith(hash); !strings.HasPrefix(got, want) {
+		t.Errorf("ParserError.CaretWith() = %s, want %s", got, want)
+	}
+	if got := (&ParserError{Func: "Value", Err: syntaxError("Value", "4111111111111111").Err}).Error(); strings.Contains(got, "4111111111111111") {
+		t.Errorf("ParserError.Error() = %s", got)
+	}
+}
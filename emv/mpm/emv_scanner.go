@@ -0,0 +1,439 @@
+package mpm
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// idTable and lengthTable hold the strings of "00" to "99" so that decoding does
+// not allocate for IDs and lengths.
+var (
+	idTable     [100]ID
+	lengthTable [100]string
+)
+
+func init() {
+	for i := range idTable {
+		s := fmt.Sprintf("%02d", i)
+		idTable[i] = ID(s)
+		lengthTable[i] = s
+	}
+}
+
+// Scanner is an allocation-light parser over a []byte payload. Unlike Parser it
+// does not convert the payload to runes; ID, length and value are read in a
+// single pass by Next, and values are sub-slices of the payload. A Scanner is
+// a small value and nested templates are scanned with a Scanner on the value.
+type Scanner struct {
+	src    []byte
+	pos    int // start of the current data object
+	next   int // start of the next data object
+	id     int // numeric ID of the current data object, -1 when it is not numeric
+	value  []byte
+	policy LengthPolicy
+	err    error
+	root   []byte // root payload
+	base   int    // byte offset of src in root
+	parent ID     // ID of the template src is the value of
+}
+
+// NewScanner ...
+func NewScanner(payload []byte, policy LengthPolicy) Scanner {
+	return Scanner{
+		src:    payload,
+		pos:    -1,
+		id:     -1,
+		policy: policy,
+		root:   payload,
+	}
+}
+
+// child returns a scanner over the value of the current data object.
+func (s *Scanner) child() Scanner {
+	c := NewScanner(s.value, s.policy)
+	c.root = s.root
+	c.base = s.base + s.next - len(s.value)
+	c.parent = s.ID()
+	return c
+}
+
+// locate records where in the root payload e happened.
+func (s *Scanner) locate(e *ParserError, declared, available int) *ParserError {
+	if s.parent != "" {
+		e.Path = append(e.Path, s.parent)
+	}
+	if s.pos+IDWordCount <= len(s.src) {
+		e.Path = append(e.Path, ID(s.src[s.pos:s.pos+IDWordCount]))
+	}
+	e.Offset = int64(utf8.RuneCount(s.root[:s.base+s.pos]))
+	e.ByteOffset = int64(s.base + s.pos)
+	e.Declared = int64(declared)
+	e.Available = int64(available)
+	e.Payload = string(s.root)
+	return e
+}
+
+// templateError returns err located at the start of the template s scans.
+func (s *Scanner) templateError(fn string, err error) *ParserError {
+	e := &ParserError{Func: fn, Err: err, Payload: string(s.root)}
+	if s.parent != "" {
+		e.Path = []ID{s.parent}
+	}
+	e.Offset = int64(utf8.RuneCount(s.root[:s.base]))
+	e.ByteOffset = int64(s.base)
+	return e
+}
+
+// Next ...
+func (s *Scanner) Next() bool {
+	const fnNext = "Scanner.Next"
+	if s.err != nil {
+		return false
+	}
+	s.pos = s.next
+	if s.pos >= len(s.src) {
+		return false
+	}
+	start := s.pos + IDWordCount + ValueLengthWordCount
+	if len(s.src) < start {
+		s.err = s.locate(outOfRangeError(fnNext, int64(s.pos), int64(len(s.src)), int64(s.pos), int64(start)), IDWordCount+ValueLengthWordCount, len(s.src)-s.pos)
+		return false
+	}
+	s.id = -1
+	if d0, d1 := s.src[s.pos], s.src[s.pos+1]; isDigit(d0) && isDigit(d1) {
+		s.id = int(d0-'0')*10 + int(d1-'0')
+	}
+	d0, d1 := s.src[s.pos+2], s.src[s.pos+3]
+	if !isDigit(d0) || !isDigit(d1) {
+		s.err = s.locate(syntaxError(fnNext, string(s.src[s.pos+2:start])), 0, 0)
+		return false
+	}
+	length := int(d0-'0')*10 + int(d1-'0')
+	end := start
+	if s.policy == LengthPolicyBytes {
+		end += length
+	} else {
+		n := 0
+		for ; n < length && end < len(s.src); n++ {
+			if c := s.src[end]; c < utf8.RuneSelf {
+				end++
+				continue
+			}
+			_, size := utf8.DecodeRune(s.src[end:])
+			end += size
+		}
+		// out of range, the rune count beyond the payload does not matter
+		end += length - n
+	}
+	if len(s.src) < end {
+		s.err = s.locate(outOfRangeError(fnNext, int64(s.pos), int64(len(s.src)), int64(start), int64(end)), length, s.available(start))
+		return false
+	}
+	if s.policy == LengthPolicyBytes && end < len(s.src) && !utf8.RuneStart(s.src[end]) {
+		s.err = s.locate(splitCharacterError(fnNext, int64(start), int64(length)), length, len(s.src)-start)
+		return false
+	}
+	s.value = s.src[start:end]
+	s.next = end
+	return true
+}
+
+// ID ...
+func (s *Scanner) ID() ID {
+	if s.id >= 0 {
+		return idTable[s.id]
+	}
+	if s.pos < 0 || s.pos+IDWordCount > len(s.src) {
+		return ID("")
+	}
+	return ID(s.src[s.pos : s.pos+IDWordCount])
+}
+
+// Value returns the value of the current data object. It is a sub-slice of the payload.
+func (s *Scanner) Value() []byte {
+	return s.value
+}
+
+// Offset returns the byte offset of the current data object in the payload.
+func (s *Scanner) Offset() int {
+	return s.pos
+}
+
+// Err ...
+func (s *Scanner) Err() error {
+	return s.err
+}
+
+func (s *Scanner) idError() error {
+	return s.locate(syntaxError("Scanner.ID", string(s.ID())), 0, 0)
+}
+
+// available returns what is left in src from start, counted under the policy.
+func (s *Scanner) available(start int) int {
+	if s.policy == LengthPolicyBytes {
+		return len(s.src) - start
+	}
+	return utf8.RuneCount(s.src[start:])
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// tlvOf returns a TLV without formatting the length.
+func tlvOf(id ID, v string) TLV {
+	return TLV{Tag: id, Length: lengthOf(utf8.RuneCountInString(v)), Value: v}
+}
+
+func lengthOf(n int) string {
+	if n < len(lengthTable) {
+		return lengthTable[n]
+	}
+	return fmt.Sprintf("%02d", n)
+}
+
+// DecodeBytes is the []byte counterpart of Decode.
+func DecodeBytes(payload []byte) (*EMVQR, error) {
+	emvqr, err := ParseEMVQRBytes(payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := emvqr.Validate(); err != nil {
+		return emvqr, err
+	}
+	return emvqr, nil
+}
+
+// ParseEMVQRBytes is the []byte counterpart of ParseEMVQR.
+// Unlike ParseEMVQR it is not driven by the struct tags of EMVQR: reflection
+// would cost the allocations the Scanner avoids, so the IDs are switched on
+// here and TestParseEMVQRBytes checks that both agree.
+func ParseEMVQRBytes(payload []byte) (*EMVQR, error) {
+	s := NewScanner(payload, detectLengthPolicyBytes(payload))
+	emvqr := &EMVQR{}
+	for s.Next() {
+		value := s.Value()
+		switch id := s.id; {
+		case id < 0:
+			return nil, s.idError()
+		case id == 0:
+			emvqr.PayloadFormatIndicator = tlvOf(IDPayloadFormatIndicator, string(value))
+		case id == 1:
+			emvqr.PointOfInitiationMethod = tlvOf(IDPointOfInitiationMethod, string(value))
+		case id <= 25:
+			if emvqr.MerchantAccountInformation == nil {
+				emvqr.MerchantAccountInformation = make(map[ID]MerchantAccountInformationTLV)
+			}
+			v := string(value)
+			emvqr.MerchantAccountInformation[idTable[id]] = MerchantAccountInformationTLV{
+				Tag:    idTable[id],
+				Length: lengthOf(utf8.RuneCountInString(v)),
+				Value:  &MerchantAccountInformation{Value: v},
+			}
+		case id <= 51:
+			t, err := scanMerchantAccountInformation(s.child())
+			if err != nil {
+				return nil, err
+			}
+			if emvqr.MerchantAccountInformation == nil {
+				emvqr.MerchantAccountInformation = make(map[ID]MerchantAccountInformationTLV)
+			}
+			emvqr.MerchantAccountInformation[idTable[id]] = MerchantAccountInformationTLV{
+				Tag:    idTable[id],
+				Length: lengthOf(utf8.RuneCount(value)),
+				Value:  t,
+			}
+		case id == 52:
+			emvqr.MerchantCategoryCode = tlvOf(IDMerchantCategoryCode, string(value))
+		case id == 53:
+			emvqr.TransactionCurrency = tlvOf(IDTransactionCurrency, string(value))
+		case id == 54:
+			emvqr.TransactionAmount = tlvOf(IDTransactionAmount, string(value))
+		case id == 55:
+			emvqr.TipOrConvenienceIndicator = tlvOf(IDTipOrConvenienceIndicator, string(value))
+		case id == 56:
+			emvqr.ValueOfConvenienceFeeFixed = tlvOf(IDValueOfConvenienceFeeFixed, string(value))
+		case id == 57:
+			emvqr.ValueOfConvenienceFeePercentage = tlvOf(IDValueOfConvenienceFeePercentage, string(value))
+		case id == 58:
+			emvqr.CountryCode = tlvOf(IDCountryCode, string(value))
+		case id == 59:
+			emvqr.MerchantName = tlvOf(IDMerchantName, string(value))
+		case id == 60:
+			emvqr.MerchantCity = tlvOf(IDMerchantCity, string(value))
+		case id == 61:
+			emvqr.PostalCode = tlvOf(IDPostalCode, string(value))
+		case id == 62:
+			t, err := scanAdditionalDataFieldTemplate(s.child())
+			if err != nil {
+				return nil, err
+			}
+			emvqr.AdditionalDataFieldTemplate = t
+		case id == 63:
+			emvqr.CRC = tlvOf(IDCRC, string(value))
+		case id == 64:
+			t, err := scanMerchantInformationLanguageTemplate(s.child())
+			if err != nil {
+				return nil, err
+			}
+			emvqr.MerchantInformationLanguageTemplate = t
+		case id <= 79:
+			emvqr.RFUforEMVCo = append(emvqr.RFUforEMVCo, tlvOf(idTable[id], string(value)))
+		default:
+			t, err := scanUnreservedTemplate(s.child())
+			if err != nil {
+				return nil, err
+			}
+			if emvqr.UnreservedTemplates == nil {
+				emvqr.UnreservedTemplates = make(map[ID]UnreservedTemplateTLV)
+			}
+			emvqr.UnreservedTemplates[idTable[id]] = UnreservedTemplateTLV{
+				Tag:    idTable[id],
+				Length: lengthOf(utf8.RuneCount(value)),
+				Value:  t,
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return emvqr, nil
+}
+
+func scanMerchantAccountInformation(s Scanner) (*MerchantAccountInformation, error) {
+	t := &MerchantAccountInformation{}
+	for s.Next() {
+		switch id := s.id; {
+		case id < 0:
+			return nil, s.idError()
+		case id == 0:
+			t.GloballyUniqueIdentifier = tlvOf(MerchantAccountInformationIDGloballyUniqueIdentifier, string(s.Value()))
+		default:
+			t.PaymentNetworkSpecific = append(t.PaymentNetworkSpecific, tlvOf(idTable[id], string(s.Value())))
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func scanAdditionalDataFieldTemplate(s Scanner) (*AdditionalDataFieldTemplate, error) {
+	t := &AdditionalDataFieldTemplate{}
+	for s.Next() {
+		id := s.id
+		if id < 0 {
+			return nil, s.idError()
+		}
+		if id == 0 {
+			continue
+		}
+		tlv := tlvOf(idTable[id], string(s.Value()))
+		switch {
+		case id == 1:
+			t.BillNumber = tlv
+		case id == 2:
+			t.MobileNumber = tlv
+		case id == 3:
+			t.StoreLabel = tlv
+		case id == 4:
+			t.LoyaltyNumber = tlv
+		case id == 5:
+			t.ReferenceLabel = tlv
+		case id == 6:
+			t.CustomerLabel = tlv
+		case id == 7:
+			t.TerminalLabel = tlv
+		case id == 8:
+			t.PurposeTransaction = tlv
+		case id == 9:
+			t.AdditionalConsumerDataRequest = tlv
+		case id <= 49:
+			t.RFUforEMVCo = append(t.RFUforEMVCo, tlv)
+		default:
+			t.PaymentSystemSpecific = append(t.PaymentSystemSpecific, tlv)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func scanMerchantInformationLanguageTemplate(s Scanner) (*MerchantInformationLanguageTemplate, error) {
+	t := &MerchantInformationLanguageTemplate{}
+	for s.Next() {
+		id := s.id
+		if id < 0 {
+			return nil, s.idError()
+		}
+		tlv := tlvOf(idTable[id], string(s.Value()))
+		switch id {
+		case 0:
+			t.LanguagePreference = tlv
+		case 1:
+			t.MerchantName = tlv
+		case 2:
+			t.MerchantCity = tlv
+		default:
+			t.RFUforEMVCo = append(t.RFUforEMVCo, tlv)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func scanUnreservedTemplate(s Scanner) (*UnreservedTemplate, error) {
+	t := &UnreservedTemplate{}
+	for s.Next() {
+		switch id := s.id; {
+		case id < 0:
+			return nil, s.idError()
+		case id == 0:
+			t.GloballyUniqueIdentifier = tlvOf(UnreservedTemplateIDGloballyUniqueIdentifier, string(s.Value()))
+		default:
+			t.ContextSpecificData = append(t.ContextSpecificData, tlvOf(idTable[id], string(s.Value())))
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if err := t.decodeExtension(); err != nil {
+		return nil, s.templateError("Scanner."+fnExtension, err)
+	}
+	return t, nil
+}
+
+// detectLengthPolicyBytes is the []byte counterpart of DetectLengthPolicy.
+func detectLengthPolicyBytes(payload []byte) LengthPolicy {
+	ascii := true
+	for _, c := range payload {
+		if c >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return LengthPolicyRunes
+	}
+	for _, policy := range []LengthPolicy{LengthPolicyRunes, LengthPolicyBytes} {
+		if wellFormedBytes(payload, policy, true) {
+			return policy
+		}
+	}
+	return LengthPolicyRunes
+}
+
+// wellFormedBytes reports whether payload, and the templates in it when root is
+// true, scan to the end under the policy.
+func wellFormedBytes(payload []byte, policy LengthPolicy, root bool) bool {
+	s := NewScanner(payload, policy)
+	for s.Next() {
+		if root && s.id >= 0 && isTemplate(idTable[s.id]) && !wellFormedBytes(s.Value(), policy, false) {
+			return false
+		}
+	}
+	return s.Err() == nil
+}
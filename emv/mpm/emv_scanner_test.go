@@ -0,0 +1,161 @@
+package mpm
+
+import (
+	"reflect"
+	"testing"
+)
+
+const benchmarkPayload = "00020101021229300012D156000000000510A93FO3230Q31280012D15600000001030812345678520441115802CN5914BEST TRANSPORT6007BEIJING64200002ZH0104最佳运输0202北京540523.7253031565502016233030412340603***0708A60086670902ME91320016A0112233449988770708123456786304A13A"
+
+func TestParseEMVQRBytes(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		wantErr bool
+	}{
+		{
+			name:    "empty payload",
+			payload: "",
+		},
+		{
+			name:    "full payload",
+			payload: benchmarkPayload,
+		},
+		{
+			name: "every root data object",
+			payload: "000201" + "010211" + "0204ABCD" + "26060002XY" + "52044111" + "5303156" + "540210" + "550202" +
+				"56011" + "57011" + "5802CN" + "5904NAME" + "6004CITY" + "610512345" + "62070503REF" + "6304ABCD" +
+				"64120002ZH0102NM" + "6502XY" + "80140002XY0104DATA" + "81310019com.example.loyalty0104GOLD",
+		},
+		{
+			name:    "byte length payload",
+			payload: "000201" + languageTemplateBytes + "6304ABCD",
+		},
+		{
+			name:    "primitive merchant account information and RFU",
+			payload: "000201021641111111111111116502XY",
+		},
+		{
+			name:    "additional data with ignored ID 00",
+			payload: "62160002XY0506REF001",
+		},
+		{
+			name:    "id parse error",
+			payload: "ab",
+			wantErr: true,
+		},
+		{
+			name:    "value parse error",
+			payload: "00020",
+			wantErr: true,
+		},
+		{
+			name:    "value length is not number",
+			payload: "00ab01",
+			wantErr: true,
+		},
+		{
+			name:    "not number id",
+			payload: "ab0201",
+			wantErr: true,
+		},
+		{
+			name:    "not number id in template",
+			payload: "2906ab0201",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEMVQRBytes([]byte(tt.payload))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseEMVQRBytes() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			want, err := ParseEMVQR(tt.payload)
+			if err != nil {
+				t.Fatalf("ParseEMVQR() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ParseEMVQRBytes() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeBytes(t *testing.T) {
+	got, err := DecodeBytes([]byte(benchmarkPayload))
+	if err != nil {
+		t.Fatalf("DecodeBytes() error = %v", err)
+	}
+	want, err := Decode(benchmarkPayload)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeBytes() = %v, want %v", got, want)
+	}
+	if _, err := DecodeBytes([]byte("000201")); err == nil {
+		t.Errorf("DecodeBytes() error = %v, wantErr %v", err, true)
+	}
+}
+
+func TestScanner_Next(t *testing.T) {
+	s := NewScanner([]byte("0004最佳运输0102ab"), LengthPolicyRunes)
+	var ids []ID
+	var values []string
+	for s.Next() {
+		ids = append(ids, s.ID())
+		values = append(values, string(s.Value()))
+	}
+	if err := s.Err(); err != nil {
+		t.Fatalf("Scanner.Err() error = %v", err)
+	}
+	if !reflect.DeepEqual(ids, []ID{"00", "01"}) {
+		t.Errorf("Scanner.ID() = %v, want %v", ids, []ID{"00", "01"})
+	}
+	if !reflect.DeepEqual(values, []string{"最佳运输", "ab"}) {
+		t.Errorf("Scanner.Value() = %v, want %v", values, []string{"最佳运输", "ab"})
+	}
+}
+
+func BenchmarkParseEMVQR(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseEMVQR(benchmarkPayload); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseEMVQRBytes(b *testing.B) {
+	payload := []byte(benchmarkPayload)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseEMVQRBytes(payload); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Decode(benchmarkPayload); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeBytes(b *testing.B) {
+	payload := []byte(benchmarkPayload)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := DecodeBytes(payload); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package mpm
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxTransactionAmountLength = 13
+	maxValueLength             = 99 // of the two digit length
+)
+
+// maxRootValueLengths are the lengths of root data objects limited below maxValueLength.
+var maxRootValueLengths = map[ID]int{
+	IDPayloadFormatIndicator:          2,
+	IDPointOfInitiationMethod:         2,
+	IDMerchantCategoryCode:            4,
+	IDTransactionCurrency:             3,
+	IDTransactionAmount:               maxTransactionAmountLength,
+	IDTipOrConvenienceIndicator:       2,
+	IDValueOfConvenienceFeeFixed:      13,
+	IDValueOfConvenienceFeePercentage: 5,
+	IDCountryCode:                     2,
+	IDMerchantName:                    25,
+	IDMerchantCity:                    15,
+	IDPostalCode:                      10,
+	IDCRC:                             4,
+}
+
+// DataObject is a root data object as it appears in a payload.
+type DataObject struct {
+	ID    ID
+	Value string
+}
+
+// WithAmount sets the Transaction Amount (54) of a payload and turns it into a
+// dynamic code (01=12). Object order and unknown IDs are kept as they are and the
+// CRC (63) is recomputed. A payload whose CRC does not match is rejected.
+func WithAmount(payload, amount string) (string, error) {
+	if err := validateAmount(amount); err != nil {
+		return "", err
+	}
+	objects, err := parseDataObjects(payload)
+	if err != nil {
+		return "", err
+	}
+	objects = setDataObject(objects, IDTransactionAmount, amount)
+	objects = setDataObject(objects, IDPointOfInitiationMethod, PointOfInitiationMethodDynamic)
+	return formatDataObjects(objects)
+}
+
+// WithPointOfInitiationMethod sets the Point of Initiation Method (01) of a payload
+// and recomputes the CRC (63).
+func WithPointOfInitiationMethod(payload, method string) (string, error) {
+	if method != PointOfInitiationMethodStatic && method != PointOfInitiationMethodDynamic {
+		return "", fmt.Errorf("PointOfInitiationMethod should be \"11\" or \"12\", PointOfInitiationMethod: %s", method)
+	}
+	objects, err := parseDataObjects(payload)
+	if err != nil {
+		return "", err
+	}
+	objects = setDataObject(objects, IDPointOfInitiationMethod, method)
+	return formatDataObjects(objects)
+}
+
+// WithDataObject replaces the root data object id of a payload, or inserts it after
+// the last object with a smaller ID when it is absent, and recomputes the CRC (63).
+// value has to fit the length of id, such as 25 characters for the Merchant Name.
+func WithDataObject(payload string, id ID, value string) (string, error) {
+	if _, err := id.ParseInt(); err != nil || len(id) != IDWordCount {
+		return "", fmt.Errorf("ID should be two digits, ID: %s", id)
+	}
+	if id == IDCRC {
+		return "", fmt.Errorf("CRC is recomputed and can not be set")
+	}
+	if err := validateRootValueLength(id, value); err != nil {
+		return "", err
+	}
+	objects, err := parseDataObjects(payload)
+	if err != nil {
+		return "", err
+	}
+	objects = setDataObject(objects, id, value)
+	return formatDataObjects(objects)
+}
+
+// parseDataObjects splits payload into its root data objects. The CRC (63) is
+// verified when present and left out.
+func parseDataObjects(payload string) ([]DataObject, error) {
+	p := NewParser(payload)
+	var objects []DataObject
+	for p.Next() {
+		id := p.ID()
+		value := p.Value()
+		if p.Err() != nil {
+			break
+		}
+		if id == IDCRC {
+			// the CRC is recomputed, but a payload that was altered is not passed on
+			want := formatCrc(string(p.source[:p.current]))[IDWordCount+ValueLengthWordCount:]
+			if !strings.EqualFold(value, want) {
+				return nil, p.locate(crcMismatchError("Value", value, want), 0, 0)
+			}
+			continue
+		}
+		objects = append(objects, DataObject{ID: id, Value: value})
+	}
+	if err := p.Err(); err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+func formatDataObjects(objects []DataObject) (string, error) {
+	var sb strings.Builder
+	for _, o := range objects {
+		if n := utf8.RuneCountInString(o.Value); n > maxValueLength {
+			return "", fmt.Errorf("value of %s should be at most %d characters, length: %d", o.ID, maxValueLength, n)
+		}
+		sb.WriteString(format(o.ID, o.Value))
+	}
+	s := sb.String()
+	return s + formatCrc(s), nil
+}
+
+// validateRootValueLength checks value against the length limit of the root data object id.
+func validateRootValueLength(id ID, value string) error {
+	limit, ok := maxRootValueLengths[id]
+	if !ok {
+		limit = maxValueLength
+	}
+	if n := utf8.RuneCountInString(value); n > limit {
+		return fmt.Errorf("value of %s should be at most %d characters, length: %d", id, limit, n)
+	}
+	return nil
+}
+
+func setDataObject(objects []DataObject, id ID, value string) []DataObject {
+	for i, o := range objects {
+		if o.ID == id {
+			objects[i].Value = value
+			return objects
+		}
+	}
+	// insert right after the last object with a smaller ID
+	at := 0
+	for i, o := range objects {
+		if o.ID < id {
+			at = i + 1
+		}
+	}
+	objects = append(objects, DataObject{})
+	copy(objects[at+1:], objects[at:])
+	objects[at] = DataObject{ID: id, Value: value}
+	return objects
+}
+
+func validateAmount(amount string) error {
+	if amount == "" || len(amount) > maxTransactionAmountLength {
+		return fmt.Errorf("TransactionAmount should be 1 to %d characters, TransactionAmount: %s", maxTransactionAmountLength, amount)
+	}
+	dot := false
+	for i, r := range amount {
+		switch {
+		case r >= '0' && r <= '9':
+		case r == '.' && !dot && i > 0 && i < len(amount)-1:
+			dot = true
+		default:
+			return fmt.Errorf("TransactionAmount should be digits with an optional decimal point between digits, TransactionAmount: %s", amount)
+		}
+	}
+	return nil
+}
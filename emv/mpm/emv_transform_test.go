@@ -0,0 +1,261 @@
+package mpm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithAmount(t *testing.T) {
+	const (
+		merchant = "29300012D156000000000510A93FO3230Q"
+		tail     = "5802CN5914BEST TRANSPORT6007BEIJING"
+	)
+	withCrc := func(s string) string {
+		return s + formatCrc(s)
+	}
+	type args struct {
+		payload string
+		amount  string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "static to dynamic",
+			args: args{
+				payload: withCrc("000201010211" + merchant + "520441115303156" + tail),
+				amount:  "23.72",
+			},
+			want: withCrc("000201010212" + merchant + "520441115303156540523.72" + tail),
+		},
+		{
+			name: "keep order and unknown IDs",
+			args: args{
+				payload: withCrc("000201" + "6902XY" + merchant + "5303156" + "52044111" + tail),
+				amount:  "1",
+			},
+			want: withCrc("000201" + "010212" + "6902XY" + merchant + "5303156" + "52044111" + "54011" + tail),
+		},
+		{
+			name: "replace amount",
+			args: args{
+				payload: withCrc("000201010212" + merchant + "520441115303156540599.99" + tail),
+				amount:  "100",
+			},
+			want: withCrc("000201010212" + merchant + "52044111530315654031005802CN5914BEST TRANSPORT6007BEIJING"),
+		},
+		{
+			name: "without crc",
+			args: args{
+				payload: "000201010211" + merchant,
+				amount:  "5",
+			},
+			want: withCrc("000201010212" + merchant + "54015"),
+		},
+		{
+			name: "invalid amount",
+			args: args{
+				payload: withCrc("000201010211" + merchant),
+				amount:  "1.2.3",
+			},
+			wantErr: true,
+		},
+		{
+			name: "amount without a leading digit",
+			args: args{
+				payload: withCrc("000201010211" + merchant),
+				amount:  ".5",
+			},
+			wantErr: true,
+		},
+		{
+			name: "too long amount",
+			args: args{
+				payload: withCrc("000201010211" + merchant),
+				amount:  "12345678901234",
+			},
+			wantErr: true,
+		},
+		{
+			name: "broken payload",
+			args: args{
+				payload: "00020",
+				amount:  "1",
+			},
+			wantErr: true,
+		},
+		{
+			name: "crc mismatch",
+			args: args{
+				payload: "000201010211" + merchant + "6304FFFF",
+				amount:  "1",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := WithAmount(tt.args.payload, tt.args.amount)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WithAmount() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("WithAmount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithPointOfInitiationMethod(t *testing.T) {
+	withCrc := func(s string) string {
+		return s + formatCrc(s)
+	}
+	type args struct {
+		payload string
+		method  string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "dynamic to static",
+			args: args{
+				payload: withCrc("000201010212540510.005802JP"),
+				method:  PointOfInitiationMethodStatic,
+			},
+			want: withCrc("000201010211540510.005802JP"),
+		},
+		{
+			name: "insert",
+			args: args{
+				payload: withCrc("0002015802JP"),
+				method:  PointOfInitiationMethodDynamic,
+			},
+			want: withCrc("0002010102125802JP"),
+		},
+		{
+			name: "lower case crc",
+			args: args{
+				payload: "0002015802JP" + strings.ToLower(formatCrc("0002015802JP")),
+				method:  PointOfInitiationMethodStatic,
+			},
+			want: withCrc("0002010102115802JP"),
+		},
+		{
+			name: "invalid method",
+			args: args{
+				payload: withCrc("0002015802JP"),
+				method:  "13",
+			},
+			wantErr: true,
+		},
+		{
+			name: "crc mismatch",
+			args: args{
+				payload: "0002015802JP63040000",
+				method:  PointOfInitiationMethodStatic,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := WithPointOfInitiationMethod(tt.args.payload, tt.args.method)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WithPointOfInitiationMethod() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("WithPointOfInitiationMethod() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithDataObject(t *testing.T) {
+	type args struct {
+		payload string
+		id      ID
+		value   string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "insert",
+			args: args{
+				payload: "0002015802JP",
+				id:      IDMerchantName,
+				value:   "DONGRI",
+			},
+			want: "0002015802JP5906DONGRI" + formatCrc("0002015802JP5906DONGRI"),
+		},
+		{
+			name: "crc can not be set",
+			args: args{
+				payload: "000201",
+				id:      IDCRC,
+				value:   "ABCD",
+			},
+			wantErr: true,
+		},
+		{
+			name: "value over 99 characters",
+			args: args{
+				payload: "000201",
+				id:      "65",
+				value:   strings.Repeat("A", 120),
+			},
+			wantErr: true,
+		},
+		{
+			name: "merchant name over 25 characters",
+			args: args{
+				payload: "000201",
+				id:      IDMerchantName,
+				value:   strings.Repeat("A", 26),
+			},
+			wantErr: true,
+		},
+		{
+			name: "merchant city of 15 characters",
+			args: args{
+				payload: "000201",
+				id:      IDMerchantCity,
+				value:   strings.Repeat("A", 15),
+			},
+			want: "000201" + "6015" + strings.Repeat("A", 15) + formatCrc("000201"+"6015"+strings.Repeat("A", 15)),
+		},
+		{
+			name: "invalid id",
+			args: args{
+				payload: "000201",
+				id:      ID("5"),
+				value:   "ABCD",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := WithDataObject(tt.args.payload, tt.args.id, tt.args.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WithDataObject() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("WithDataObject() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,8 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"regexp"
-	"sort"
 	"strconv"
 	"strings"
 	"unicode/utf8"
@@ -91,25 +91,27 @@ const (
 )
 
 // EMVQR ...
+// Its fields are tagged for Marshal and Unmarshal, which GeneratePayload and
+// ParseEMVQR are built on.
 type EMVQR struct {
-	PayloadFormatIndicator              TLV                                  `json:"Payload Format Indicator"`
-	PointOfInitiationMethod             TLV                                  `json:"Point of Initiation Method"`
-	MerchantAccountInformation          map[ID]MerchantAccountInformationTLV `json:"Merchant Account Information"`
-	MerchantCategoryCode                TLV                                  `json:"Merchant Category Code"`
-	TransactionCurrency                 TLV                                  `json:"Transaction Currency"`
-	TransactionAmount                   TLV                                  `json:"Transaction Amount"`
-	TipOrConvenienceIndicator           TLV                                  `json:"Tip or Convenience Indicator"`
-	ValueOfConvenienceFeeFixed          TLV                                  `json:"Value of Convenience Fee Fixed"`
-	ValueOfConvenienceFeePercentage     TLV                                  `json:"Value of Convenience Fee Percentage"`
-	CountryCode                         TLV                                  `json:"Country Code"`
-	MerchantName                        TLV                                  `json:"Merchant Name"`
-	MerchantCity                        TLV                                  `json:"Merchant City"`
-	PostalCode                          TLV                                  `json:"Postal Code"`
-	AdditionalDataFieldTemplate         *AdditionalDataFieldTemplate         `json:"Additional Data Field Template"`
-	CRC                                 TLV                                  `json:"CRC"`
-	MerchantInformationLanguageTemplate *MerchantInformationLanguageTemplate `json:"Merchant Information - Language Template"`
-	RFUforEMVCo                         []TLV                                `json:"RFU for EMVCo"`
-	UnreservedTemplates                 map[ID]UnreservedTemplateTLV         `json:"Unreserved Templates"`
+	PayloadFormatIndicator              TLV                                  `json:"Payload Format Indicator" emv:"00"`
+	PointOfInitiationMethod             TLV                                  `json:"Point of Initiation Method" emv:"01"`
+	MerchantAccountInformation          map[ID]MerchantAccountInformationTLV `json:"Merchant Account Information" emv:"02-51,template=26-51"`
+	MerchantCategoryCode                TLV                                  `json:"Merchant Category Code" emv:"52"`
+	TransactionCurrency                 TLV                                  `json:"Transaction Currency" emv:"53"`
+	TransactionAmount                   TLV                                  `json:"Transaction Amount" emv:"54"`
+	TipOrConvenienceIndicator           TLV                                  `json:"Tip or Convenience Indicator" emv:"55"`
+	ValueOfConvenienceFeeFixed          TLV                                  `json:"Value of Convenience Fee Fixed" emv:"56"`
+	ValueOfConvenienceFeePercentage     TLV                                  `json:"Value of Convenience Fee Percentage" emv:"57"`
+	CountryCode                         TLV                                  `json:"Country Code" emv:"58"`
+	MerchantName                        TLV                                  `json:"Merchant Name" emv:"59"`
+	MerchantCity                        TLV                                  `json:"Merchant City" emv:"60"`
+	PostalCode                          TLV                                  `json:"Postal Code" emv:"61"`
+	AdditionalDataFieldTemplate         *AdditionalDataFieldTemplate         `json:"Additional Data Field Template" emv:"62,template"`
+	CRC                                 TLV                                  `json:"CRC" emv:"63,crc"`
+	MerchantInformationLanguageTemplate *MerchantInformationLanguageTemplate `json:"Merchant Information - Language Template" emv:"64,template"`
+	RFUforEMVCo                         []TLV                                `json:"RFU for EMVCo" emv:"65-79"`
+	UnreservedTemplates                 map[ID]UnreservedTemplateTLV         `json:"Unreserved Templates" emv:"80-99,template"`
 }
 
 // MerchantAccountInformationTLV ...
@@ -124,31 +126,31 @@ type MerchantAccountInformationTLV struct {
 // For tag 26-51 (template), GloballyUniqueIdentifier and PaymentNetworkSpecific are populated.
 type MerchantAccountInformation struct {
 	Value                    string `json:"Value,omitempty"`
-	GloballyUniqueIdentifier TLV    `json:"Globally Unique Identifier"`
-	PaymentNetworkSpecific   []TLV  `json:"Payment network specific"`
+	GloballyUniqueIdentifier TLV    `json:"Globally Unique Identifier" emv:"00"`
+	PaymentNetworkSpecific   []TLV  `json:"Payment network specific" emv:"01-99"`
 }
 
 // AdditionalDataFieldTemplate ...
 type AdditionalDataFieldTemplate struct {
-	BillNumber                    TLV   `json:"Bill Number"`
-	MobileNumber                  TLV   `json:"Country Code"`
-	StoreLabel                    TLV   `json:"Store Label"`
-	LoyaltyNumber                 TLV   `json:"Loyalty Number"`
-	ReferenceLabel                TLV   `json:"Reference Label"`
-	CustomerLabel                 TLV   `json:"Customer Label"`
-	TerminalLabel                 TLV   `json:"Terminal Label"`
-	PurposeTransaction            TLV   `json:"Purpose of Transaction"`
-	AdditionalConsumerDataRequest TLV   `json:"Additional Consumer Data Request"`
-	RFUforEMVCo                   []TLV `json:"RFU for EMVCo"`
-	PaymentSystemSpecific         []TLV `json:"Payment System specific templates"`
+	BillNumber                    TLV   `json:"Bill Number" emv:"01"`
+	MobileNumber                  TLV   `json:"Country Code" emv:"02"`
+	StoreLabel                    TLV   `json:"Store Label" emv:"03"`
+	LoyaltyNumber                 TLV   `json:"Loyalty Number" emv:"04"`
+	ReferenceLabel                TLV   `json:"Reference Label" emv:"05"`
+	CustomerLabel                 TLV   `json:"Customer Label" emv:"06"`
+	TerminalLabel                 TLV   `json:"Terminal Label" emv:"07"`
+	PurposeTransaction            TLV   `json:"Purpose of Transaction" emv:"08"`
+	AdditionalConsumerDataRequest TLV   `json:"Additional Consumer Data Request" emv:"09"`
+	RFUforEMVCo                   []TLV `json:"RFU for EMVCo" emv:"10-49"`
+	PaymentSystemSpecific         []TLV `json:"Payment System specific templates" emv:"50-99"`
 }
 
 // MerchantInformationLanguageTemplate ...
 type MerchantInformationLanguageTemplate struct {
-	LanguagePreference TLV   `json:"Language Preference"`
-	MerchantName       TLV   `json:"Merchant Name"`
-	MerchantCity       TLV   `json:"Merchant City"`
-	RFUforEMVCo        []TLV `json:"RFU for EMVCo"`
+	LanguagePreference TLV   `json:"Language Preference" emv:"00"`
+	MerchantName       TLV   `json:"Merchant Name" emv:"01"`
+	MerchantCity       TLV   `json:"Merchant City" emv:"02"`
+	RFUforEMVCo        []TLV `json:"RFU for EMVCo" emv:"03-99"`
 }
 
 // UnreservedTemplateTLV ...
@@ -159,9 +161,12 @@ type UnreservedTemplateTLV struct {
 }
 
 // UnreservedTemplate ...
+// Extension is a pointer to the struct registered for the GUI with
+// RegisterExtension, decoded from ContextSpecificData, or nil.
 type UnreservedTemplate struct {
-	GloballyUniqueIdentifier TLV   `json:"Globally Unique Identifier"`
-	ContextSpecificData      []TLV `json:"Context Specific Data"`
+	GloballyUniqueIdentifier TLV         `json:"Globally Unique Identifier" emv:"00"`
+	ContextSpecificData      []TLV       `json:"Context Specific Data" emv:"01-99"`
+	Extension                interface{} `json:"Extension,omitempty"`
 }
 
 // DataType ...
@@ -182,7 +187,13 @@ func (id ID) String() string {
 }
 
 // ParseInt ...
+// Signs are rejected, an ID is made of digits only.
 func (id ID) ParseInt() (int64, error) {
+	for i := 0; i < len(id); i++ {
+		if !isDigit(id[i]) {
+			return 0, &strconv.NumError{Func: "ParseInt", Num: id.String(), Err: strconv.ErrSyntax}
+		}
+	}
 	return strconv.ParseInt(id.String(), 10, 64)
 }
 
@@ -239,19 +250,19 @@ func (tlv TLV) DataWithType(dataType DataType, indent string) string {
 	return ""
 }
 
-// BinaryData ...
+// BinaryData returns c in hex, with the PANs found in its values redacted by PrintRedactor.
 func (c *EMVQR) BinaryData() string {
-	return c.dataWithType(DataTypeBinary)
+	return c.Redact(PrintRedactor).dataWithType(DataTypeBinary)
 }
 
-// RawData ...
+// RawData returns c as is, with the PANs found in its values redacted by PrintRedactor.
 func (c *EMVQR) RawData() string {
-	return c.dataWithType(DataTypeRaw)
+	return c.Redact(PrintRedactor).dataWithType(DataTypeRaw)
 }
 
-// JSON ...
+// JSON returns c in JSON, with the PANs found in its values redacted by PrintRedactor.
 func (c *EMVQR) JSON() string {
-	bytes, _ := json.Marshal(c)
+	bytes, _ := json.Marshal(c.Redact(PrintRedactor))
 	return string(bytes)
 }
 
@@ -467,9 +478,8 @@ func (s *MerchantAccountInformationTLV) String() string {
 	if s == nil {
 		return ""
 	}
-	t := ""
-	t += s.Tag.String() + s.Length + s.Value.String()
-	return t
+	// the length of an extension may have changed since it was set
+	return format(s.Tag, s.Value.String())
 }
 
 // DataWithType ..
@@ -508,18 +518,23 @@ func (s *MerchantAccountInformation) String() string {
 	if s == nil {
 		return ""
 	}
+	v, _ := s.MarshalEMV()
+	return v
+}
+
+// MarshalEMV returns Value for primitive Merchant Account Information (02-25),
+// the data objects of the template otherwise.
+func (s *MerchantAccountInformation) MarshalEMV() (string, error) {
 	if s.Value != "" {
-		return s.Value
-	}
-	t := ""
-	t += s.GloballyUniqueIdentifier.String()
-	sort.Slice(s.PaymentNetworkSpecific, func(i, j int) bool {
-		return s.PaymentNetworkSpecific[i].Tag < s.PaymentNetworkSpecific[j].Tag
-	})
-	for _, pns := range s.PaymentNetworkSpecific {
-		t += pns.String()
+		return s.Value, nil
 	}
-	return t
+	return marshalTemplate(reflect.ValueOf(s).Elem(), false)
+}
+
+// UnmarshalEMV sets the Value of primitive Merchant Account Information (02-25).
+func (s *MerchantAccountInformation) UnmarshalEMV(value string) error {
+	s.Value = value
+	return nil
 }
 
 // DataWithType ...
@@ -654,24 +669,8 @@ func (s *AdditionalDataFieldTemplate) String() string {
 	if s == nil {
 		return ""
 	}
-	t := ""
-	t += s.BillNumber.String()
-	t += s.MobileNumber.String()
-	t += s.StoreLabel.String()
-	t += s.LoyaltyNumber.String()
-	t += s.ReferenceLabel.String()
-	t += s.CustomerLabel.String()
-	t += s.TerminalLabel.String()
-	t += s.PurposeTransaction.String()
-	t += s.AdditionalConsumerDataRequest.String()
-	for _, r := range s.RFUforEMVCo {
-		t += r.String()
-	}
-	for _, p := range s.PaymentSystemSpecific {
-		t += p.String()
-	}
-	tt := format(IDAdditionalDataFieldTemplate, t)
-	return tt
+	t, _ := marshalTemplate(reflect.ValueOf(s).Elem(), false)
+	return format(IDAdditionalDataFieldTemplate, t)
 }
 
 // DataWithType ...
@@ -746,15 +745,8 @@ func (s *MerchantInformationLanguageTemplate) String() string {
 	if s == nil {
 		return ""
 	}
-	t := ""
-	t += s.LanguagePreference.String()
-	t += s.MerchantName.String()
-	t += s.MerchantCity.String()
-	for _, r := range s.RFUforEMVCo {
-		t += r.String()
-	}
-	t = format(IDMerchantInformationLanguageTemplate, t)
-	return t
+	t, _ := marshalTemplate(reflect.ValueOf(s).Elem(), false)
+	return format(IDMerchantInformationLanguageTemplate, t)
 }
 
 // DataWithType ...
@@ -779,9 +771,8 @@ func (s *UnreservedTemplateTLV) String() string {
 	if s == nil {
 		return ""
 	}
-	t := ""
-	t += s.Tag.String() + s.Length + s.Value.String()
-	return t
+	// the length of an extension may have changed since it was set
+	return format(s.Tag, s.Value.String())
 }
 
 // DataWithType ..
@@ -789,7 +780,12 @@ func (s *UnreservedTemplateTLV) DataWithType(dataType DataType, indent string) s
 	if s == nil {
 		return ""
 	}
-	return s.Tag.String() + " " + s.Length + "\n" + s.Value.DataWithType(dataType, indent)
+	length := s.Length
+	if s.Value != nil && s.Value.Extension != nil {
+		// the length of an extension may have changed since it was set
+		length = l(s.Value.String())
+	}
+	return s.Tag.String() + " " + length + "\n" + s.Value.DataWithType(dataType, indent)
 }
 
 // SetGloballyUniqueIdentifier ...
@@ -817,11 +813,7 @@ func (s *UnreservedTemplate) String() string {
 	if s == nil {
 		return ""
 	}
-	t := ""
-	t += s.GloballyUniqueIdentifier.String()
-	for _, c := range s.ContextSpecificData {
-		t += c.String()
-	}
+	t, _ := s.MarshalEMV()
 	return t
 }
 
@@ -830,6 +822,9 @@ func (s *UnreservedTemplate) DataWithType(dataType DataType, indent string) stri
 	if s == nil {
 		return ""
 	}
+	if t, err := s.synced(); err == nil {
+		s = t
+	}
 	var csData string
 	for _, cs := range s.ContextSpecificData {
 		csData += indent + cs.DataWithType(dataType, indent)
@@ -839,144 +834,33 @@ func (s *UnreservedTemplate) DataWithType(dataType DataType, indent string) stri
 
 //////////////////////////////////////////////////////////////////////////
 
-// GeneratePayload ...
+// GeneratePayload returns the payload of c, or "" if c can not be encoded,
+// such as with an ID out of its range. MarshalPayload returns the error.
 func (c *EMVQR) GeneratePayload() string {
-	s := ""
-	s += c.PayloadFormatIndicator.String()
-	s += c.PointOfInitiationMethod.String()
-	var keys []string
-	for k := range c.MerchantAccountInformation {
-		keys = append(keys, k.String())
-	}
-	sort.Strings(keys)
-	for _, k := range keys {
-		v := c.MerchantAccountInformation[ID(k)]
-		s += v.String()
-	}
-	s += c.MerchantCategoryCode.String()
-	s += c.TransactionCurrency.String()
-	s += c.TransactionAmount.String()
-	s += c.TipOrConvenienceIndicator.String()
-	s += c.ValueOfConvenienceFeeFixed.String()
-	s += c.ValueOfConvenienceFeePercentage.String()
-	s += c.CountryCode.String()
-	s += c.MerchantName.String()
-	s += c.MerchantCity.String()
-	s += c.PostalCode.String()
-	s += c.AdditionalDataFieldTemplate.String()
-	s += c.MerchantInformationLanguageTemplate.String()
-	for _, r := range c.RFUforEMVCo {
-		s += r.String()
-	}
-	for _, u := range c.UnreservedTemplates {
-		s += u.String()
+	payload, err := c.MarshalPayload()
+	if err != nil {
+		return ""
 	}
-	s += formatCrc(s)
-	return s
+	return payload
+}
+
+// MarshalPayload returns the payload of c, or the error if c can not be
+// encoded. Unlike Encode it does not Validate c.
+func (c *EMVQR) MarshalPayload() (string, error) {
+	return Marshal(c)
 }
 
 // ParseEMVQR ...
 func ParseEMVQR(payload string) (*EMVQR, error) {
-	p := NewParser(payload)
-	emvqr := &EMVQR{}
-	for p.Next() {
-		id := p.ID()
-		// length := p.ValueLength()
-		value := p.Value()
-		switch id {
-		case IDPayloadFormatIndicator:
-			emvqr.SetPayloadFormatIndicator(value)
-		case IDPointOfInitiationMethod:
-			emvqr.SetPointOfInitiationMethod(value)
-		case IDMerchantCategoryCode:
-			emvqr.SetMerchantCategoryCode(value)
-		case IDTransactionCurrency:
-			emvqr.SetTransactionCurrency(value)
-		case IDTransactionAmount:
-			emvqr.SetTransactionAmount(value)
-		case IDTipOrConvenienceIndicator:
-			emvqr.SetTipOrConvenienceIndicator(value)
-		case IDValueOfConvenienceFeeFixed:
-			emvqr.SetValueOfConvenienceFeeFixed(value)
-		case IDValueOfConvenienceFeePercentage:
-			emvqr.SetValueOfConvenienceFeePercentage(value)
-		case IDCountryCode:
-			emvqr.SetCountryCode(value)
-		case IDMerchantName:
-			emvqr.SetMerchantName(value)
-		case IDMerchantCity:
-			emvqr.SetMerchantCity(value)
-		case IDPostalCode:
-			emvqr.SetPostalCode(value)
-		case IDAdditionalDataFieldTemplate:
-			adft, err := ParseAdditionalDataFieldTemplate(value)
-			if err != nil {
-				return nil, err
-			}
-			emvqr.AdditionalDataFieldTemplate = adft
-		case IDCRC:
-			emvqr.SetCRC(value)
-		case IDMerchantInformationLanguageTemplate:
-			t, err := ParseMerchantInformationLanguageTemplate(value)
-			if err != nil {
-				return nil, err
-			}
-			emvqr.SetMerchantInformationLanguageTemplate(t)
-		default:
-			var (
-				within bool
-				err    error
-			)
-			// Merchant Account Information (primitive: 02-25)
-			within, err = id.Between(IDMerchantAccountInformationPrimitiveRangeStart, IDMerchantAccountInformationPrimitiveRangeEnd)
-			if err != nil {
-				return nil, err
-			}
-			if within {
-				emvqr.AddMerchantAccountInformation(id, &MerchantAccountInformation{Value: value})
-				continue
-			}
-			// Merchant Account Information (template: 26-51)
-			within, err = id.Between(IDMerchantAccountInformationTemplateRangeStart, IDMerchantAccountInformationTemplateRangeEnd)
-			if err != nil {
-				return nil, err
-			}
-			if within {
-				t, err := ParseMerchantAccountInformation(value)
-				if err != nil {
-					return nil, err
-				}
-				emvqr.AddMerchantAccountInformation(id, t)
-				continue
-			}
-			// RFUforEMVCo
-			within, err = id.Between(IDRFUForEMVCoRangeStart, IDRFUForEMVCoRangeEnd)
-			if err != nil {
-				return nil, err
-			}
-			if within {
-				emvqr.AddRFUforEMVCo(id, value)
-				continue
-			}
-			// Unreserved Tempaltes
-			within, err = id.Between(IDUnreservedTemplatesRangeStart, IDUnreservedTemplatesRangeEnd)
-			if err != nil {
-				return nil, err
-			}
-			if within {
-				t, err := ParseUnreservedTemplate(value)
-				if err != nil {
-					return nil, err
-				}
-				emvqr.AddUnreservedTemplates(id, t)
-				continue
-			}
-		}
-	}
-	if err := p.Err(); err != nil {
+	return parseEMVQR(NewParserWithLengthPolicy(payload, DetectLengthPolicy(payload)))
+}
+
+func parseEMVQR(p *Parser) (*EMVQR, error) {
+	t := &EMVQR{}
+	if err := unmarshalTemplate(p, reflect.ValueOf(t).Elem()); err != nil {
 		return nil, err
 	}
-	return emvqr, nil
+	return t, nil
 }
 
 // Validate ...
@@ -1009,167 +893,72 @@ func (c *EMVQR) Validate() error {
 			return fmt.Errorf("PointOfInitiationMethod should be \"11\" or \"12\", PointOfInitiationMethod: %s", c)
 		}
 	}
+	if err := c.validateCharacterSet(); err != nil {
+		return err
+	}
+	if c.AdditionalDataFieldTemplate != nil {
+		if err := c.AdditionalDataFieldTemplate.Validate(); err != nil {
+			return err
+		}
+	}
 	if c.MerchantInformationLanguageTemplate != nil {
 		if err := c.MerchantInformationLanguageTemplate.Validate(); err != nil {
 			return err
 		}
 	}
-	return nil
+	return c.validateGUIs()
 }
 
 // ParseAdditionalDataFieldTemplate ...
 func ParseAdditionalDataFieldTemplate(payload string) (*AdditionalDataFieldTemplate, error) {
-	p := NewParser(payload)
-	additionalDataFieldTemplate := &AdditionalDataFieldTemplate{}
-	for p.Next() {
-		id := p.ID()
-		value := p.Value()
-		switch id {
-		case AdditionalIDBillNumber:
-			additionalDataFieldTemplate.SetBillNumber(value)
-		case AdditionalIDMobileNumber:
-			additionalDataFieldTemplate.SetMobileNumber(value)
-		case AdditionalIDStoreLabel:
-			additionalDataFieldTemplate.SetStoreLabel(value)
-		case AdditionalIDLoyaltyNumber:
-			additionalDataFieldTemplate.SetLoyaltyNumber(value)
-		case AdditionalIDReferenceLabel:
-			additionalDataFieldTemplate.SetReferenceLabel(value)
-		case AdditionalIDCustomerLabel:
-			additionalDataFieldTemplate.SetCustomerLabel(value)
-		case AdditionalIDTerminalLabel:
-			additionalDataFieldTemplate.SetTerminalLabel(value)
-		case AdditionalIDPurposeTransaction:
-			additionalDataFieldTemplate.SetPurposeTransaction(value)
-		case AdditionalIDAdditionalConsumerDataRequest:
-			additionalDataFieldTemplate.SetAdditionalConsumerDataRequest(value)
-		default:
-			var (
-				within bool
-				err    error
-			)
-			// Payment System Specific
-			within, err = id.Between(AdditionalIDPaymentSystemSpecificTemplatesRangeStart, AdditionalIDPaymentSystemSpecificTemplatesRangeEnd)
-			if err != nil {
-				return nil, err
-			}
-			if within {
-				additionalDataFieldTemplate.AddPaymentSystemSpecific(id, value)
-				continue
-			}
-			// RFU for EMVCo
-			within, err = id.Between(AdditionalIDRFUforEMVCoRangeStart, AdditionalIDRFUforEMVCoRangeEnd)
-			if err != nil {
-				return nil, err
-			}
-			if within {
-				additionalDataFieldTemplate.AddRFUforEMVCo(id, value)
-				continue
-			}
-		}
-	}
-	if err := p.Err(); err != nil {
+	return parseAdditionalDataFieldTemplate(NewParser(payload))
+}
+
+func parseAdditionalDataFieldTemplate(p *Parser) (*AdditionalDataFieldTemplate, error) {
+	t := &AdditionalDataFieldTemplate{}
+	if err := unmarshalTemplate(p, reflect.ValueOf(t).Elem()); err != nil {
 		return nil, err
 	}
-	return additionalDataFieldTemplate, nil
+	return t, nil
 }
 
 // ParseMerchantAccountInformation ...
 func ParseMerchantAccountInformation(value string) (*MerchantAccountInformation, error) {
-	p := NewParser(value)
-	merchantAccountInformation := &MerchantAccountInformation{}
-	for p.Next() {
-		id := p.ID()
-		value := p.Value()
-		switch id {
-		case MerchantAccountInformationIDGloballyUniqueIdentifier:
-			merchantAccountInformation.SetGloballyUniqueIdentifier(value)
-		default:
-			var (
-				within bool
-				err    error
-			)
-			within, err = id.Between(MerchantAccountInformationIDPaymentNetworkSpecificStart, MerchantAccountInformationIDPaymentNetworkSpecificEnd)
-			if err != nil {
-				return nil, err
-			}
-			if within {
-				merchantAccountInformation.AddPaymentNetworkSpecific(id, value)
-				continue
-			}
-		}
-	}
-	if err := p.Err(); err != nil {
+	return parseMerchantAccountInformation(NewParser(value))
+}
+
+func parseMerchantAccountInformation(p *Parser) (*MerchantAccountInformation, error) {
+	t := &MerchantAccountInformation{}
+	if err := unmarshalTemplate(p, reflect.ValueOf(t).Elem()); err != nil {
 		return nil, err
 	}
-	return merchantAccountInformation, nil
+	return t, nil
 }
 
 // ParseMerchantInformationLanguageTemplate ...
 func ParseMerchantInformationLanguageTemplate(value string) (*MerchantInformationLanguageTemplate, error) {
-	p := NewParser(value)
-	merchantInformationLanguageTemplate := &MerchantInformationLanguageTemplate{}
-	for p.Next() {
-		id := p.ID()
-		value := p.Value()
-		switch id {
-		case MerchantInformationIDLanguagePreference:
-			merchantInformationLanguageTemplate.SetLanguagePreference(value)
-		case MerchantInformationIDMerchantName:
-			merchantInformationLanguageTemplate.SetMerchantName(value)
-		case MerchantInformationIDMerchantCity:
-			merchantInformationLanguageTemplate.SetMerchantCity(value)
-		default:
-			var (
-				within bool
-				err    error
-			)
-			// RFU for EMVCo
-			within, err = id.Between(MerchantInformationIDRFUforEMVCoRangeStart, MerchantInformationIDRFUforEMVCoRangeEnd)
-			if err != nil {
-				return nil, err
-			}
-			if within {
-				merchantInformationLanguageTemplate.AddRFUForEMVCo(id, value)
-				continue
-			}
-		}
-	}
-	if err := p.Err(); err != nil {
+	return parseMerchantInformationLanguageTemplate(NewParser(value))
+}
+
+func parseMerchantInformationLanguageTemplate(p *Parser) (*MerchantInformationLanguageTemplate, error) {
+	t := &MerchantInformationLanguageTemplate{}
+	if err := unmarshalTemplate(p, reflect.ValueOf(t).Elem()); err != nil {
 		return nil, err
 	}
-	return merchantInformationLanguageTemplate, nil
+	return t, nil
 }
 
 // ParseUnreservedTemplate ...
 func ParseUnreservedTemplate(value string) (*UnreservedTemplate, error) {
-	p := NewParser(value)
-	unreservedTemplate := &UnreservedTemplate{}
-	for p.Next() {
-		id := p.ID()
-		value := p.Value()
-		switch id {
-		case UnreservedTemplateIDGloballyUniqueIdentifier:
-			unreservedTemplate.SetGloballyUniqueIdentifier(value)
-		default:
-			var (
-				within bool
-				err    error
-			)
-			within, err = id.Between(UnreservedTemplateIDContextSpecificDataStart, UnreservedTemplateIDContextSpecificDataEnd)
-			if err != nil {
-				return nil, err
-			}
-			if within {
-				unreservedTemplate.AddContextSpecificData(id, value)
-				continue
-			}
-		}
-	}
-	if err := p.Err(); err != nil {
+	return parseUnreservedTemplate(NewParser(value))
+}
+
+func parseUnreservedTemplate(p *Parser) (*UnreservedTemplate, error) {
+	t := &UnreservedTemplate{}
+	if err := unmarshalTemplate(p, reflect.ValueOf(t).Elem()); err != nil {
 		return nil, err
 	}
-	return unreservedTemplate, nil
+	return t, nil
 }
 
 // Validate ...
@@ -1181,7 +970,7 @@ func (s *MerchantInformationLanguageTemplate) Validate() error {
 	if s.MerchantName.Value == "" {
 		return errors.New("MerchantName is mandatory")
 	}
-	return nil
+	return s.validateLanguage()
 }
 
 func format(id ID, value string) string {
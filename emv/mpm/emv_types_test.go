@@ -44,6 +44,12 @@ func TestID_ParseInt(t *testing.T) {
 			want:    0,
 			wantErr: true,
 		},
+		{
+			name:    "sign",
+			id:      ID("+1"),
+			want:    0,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1401,12 +1407,12 @@ func TestEMVQR_Validate(t *testing.T) {
 				UnreservedTemplates: map[ID]UnreservedTemplateTLV{
 					ID("80"): {
 						Tag:    "80",
-						Length: "16",
+						Length: "20",
 						Value: &UnreservedTemplate{
 							GloballyUniqueIdentifier: TLV{
 								Tag:    "00",
-								Length: "04",
-								Value:  "abcd",
+								Length: "08",
+								Value:  "com.abcd",
 							},
 							ContextSpecificData: []TLV{
 								{
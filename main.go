@@ -14,12 +14,12 @@ func main() {
 	emvqr.SetPayloadFormatIndicator("01")
 	emvqr.SetPointOfInitiationMethod("12") // 11 is static qrcode
 	merchantAccountInformationJCB := new(mpm.MerchantAccountInformation)
-	merchantAccountInformationJCB.SetGloballyUniqueIdentifier("D123456")
+	merchantAccountInformationJCB.SetGloballyUniqueIdentifier("A0000000651010")
 	merchantAccountInformationJCB.AddPaymentNetworkSpecific("13", "JCB1234567890")
 	emvqr.AddMerchantAccountInformation(mpm.ID("29"), merchantAccountInformationJCB)
 
 	merchantAccountInformationMaster := new(mpm.MerchantAccountInformation)
-	merchantAccountInformationMaster.SetGloballyUniqueIdentifier("M123456")
+	merchantAccountInformationMaster.SetGloballyUniqueIdentifier("A0000000041010")
 	merchantAccountInformationMaster.AddPaymentNetworkSpecific("04", "MASTER1234567890")
 	emvqr.AddMerchantAccountInformation(mpm.ID("31"), merchantAccountInformationMaster)
 
@@ -39,7 +39,7 @@ func main() {
 		log.Println(err.Error())
 		return
 	}
-	log.Println(code) // 0002010102121313JCB12345678900416MASTER12345678905204531153033925407999.1235802JP5906DONGRI6005TOKYO62240104hoge0504fuga0704piyo6304C343
+	log.Println(code) // 00020101021229350014A00000006510101313JCB123456789031380014A00000000410100416MASTER12345678905204531153033925407999.1235802JP5906DONGRI6005TOKYO62240104hoge0504fuga0704piyo6304E7B3
 
 	// MPM Decode
 	emvqr, err = mpm.Decode("00020101021229300012D156000000000510A93FO3230Q31280012D15600000001030812345678520441115802CN5914BEST TRANSPORT6007BEIJING64200002ZH0104最佳运输0202北京540523.7253031565502016233030412340603***0708A60086670902ME91320016A0112233449988770708123456786304A13A")
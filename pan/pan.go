@@ -0,0 +1,152 @@
+// Package pan validates Primary Account Numbers: the Luhn check digit and the
+// length of the card brand, identified from an embedded table of BIN ranges.
+package pan
+
+import (
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Brand is a card brand. The names are the payment network names of mpm.
+type Brand string
+
+// const ...
+const (
+	BrandAmex       Brand = "Amex"
+	BrandDinersClub Brand = "Diners Club"
+	BrandDiscover   Brand = "Discover"
+	BrandJCB        Brand = "JCB"
+	BrandMastercard Brand = "Mastercard"
+	BrandUnionPay   Brand = "UnionPay"
+	BrandVisa       Brand = "Visa"
+)
+
+// Network returns the payment network that accepts the cards of the brand,
+// as named by mpm.NetworkName.
+func (b Brand) Network() string {
+	if b == BrandDinersClub {
+		return string(BrandDiscover)
+	}
+	return string(b)
+}
+
+// BINRange is a range of issuer identification numbers of a brand. Start and
+// End are prefixes of the same number of digits.
+type BINRange struct {
+	Start   string
+	End     string
+	Brand   Brand
+	Lengths []int
+}
+
+//go:embed bins.txt
+var binsTxt string
+
+var bins = parseBINs(binsTxt)
+
+func parseBINs(data string) []BINRange {
+	var ranges []BINRange
+	for i, line := range strings.Split(data, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 4 || len(fields[0]) != len(fields[1]) {
+			panic(fmt.Sprintf("pan: bins.txt:%d: want start, end, brand and lengths", i+1))
+		}
+		r := BINRange{Start: fields[0], End: fields[1], Brand: Brand(fields[2])}
+		for _, s := range strings.Split(fields[3], ",") {
+			from, to := s, s
+			if j := strings.Index(s, "-"); j >= 0 {
+				from, to = s[:j], s[j+1:]
+			}
+			min, err1 := strconv.Atoi(from)
+			max, err2 := strconv.Atoi(to)
+			if err1 != nil || err2 != nil {
+				panic(fmt.Sprintf("pan: bins.txt:%d: bad lengths %s", i+1, fields[3]))
+			}
+			for n := min; n <= max; n++ {
+				r.Lengths = append(r.Lengths, n)
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// Luhn reports whether pan is digits with a valid Luhn check digit.
+func Luhn(pan string) bool {
+	if pan == "" {
+		return false
+	}
+	sum := 0
+	for i := 0; i < len(pan); i++ {
+		c := pan[len(pan)-1-i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// Lookup returns the BIN range of pan, the one with the longest prefix if
+// several match.
+func Lookup(pan string) (BINRange, bool) {
+	var found BINRange
+	ok := false
+	for _, r := range bins {
+		n := len(r.Start)
+		if len(pan) < n || (ok && n <= len(found.Start)) {
+			continue
+		}
+		if prefix := pan[:n]; prefix >= r.Start && prefix <= r.End {
+			found, ok = r, true
+		}
+	}
+	return found, ok
+}
+
+// BrandOf returns the brand of pan, or "" if it is not known.
+func BrandOf(pan string) Brand {
+	r, _ := Lookup(pan)
+	return r.Brand
+}
+
+// Validate checks that pan is 8 to 19 digits with a valid Luhn check digit,
+// and of a length of its brand.
+func Validate(pan string) error {
+	if len(pan) < 8 || len(pan) > 19 {
+		return fmt.Errorf("PAN should be 8 to 19 digits, length: %d", len(pan))
+	}
+	if !Luhn(pan) {
+		// the PAN is left out, errors end up in logs
+		return fmt.Errorf("PAN should pass the Luhn check, length: %d", len(pan))
+	}
+	if r, ok := Lookup(pan); ok {
+		for _, n := range r.Lengths {
+			if len(pan) == n {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s PAN should be %s digits, length: %d", r.Brand, lengths(r.Lengths), len(pan))
+	}
+	return nil
+}
+
+func lengths(ns []int) string {
+	s := make([]string, len(ns))
+	for i, n := range ns {
+		s[i] = strconv.Itoa(n)
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,77 @@
+package pan
+
+import (
+	"testing"
+)
+
+func TestLuhn(t *testing.T) {
+	tests := []struct {
+		pan  string
+		want bool
+	}{
+		{"4111111111111111", true},
+		{"4111111111111112", false},
+		{"378282246310005", true},
+		{"79927398713", true},
+		{"1234567890123458", false},
+		{"", false},
+		{"4111-1111", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pan, func(t *testing.T) {
+			if got := Luhn(tt.pan); got != tt.want {
+				t.Errorf("Luhn() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBrandOf(t *testing.T) {
+	tests := []struct {
+		pan  string
+		want Brand
+	}{
+		{"4111111111111111", BrandVisa},
+		{"5555555555554444", BrandMastercard},
+		{"2223003122003222", BrandMastercard},
+		{"378282246310005", BrandAmex},
+		{"6011111111111117", BrandDiscover},
+		{"6500000000000002", BrandDiscover},
+		{"3530111333300000", BrandJCB},
+		{"6200000000000005", BrandUnionPay},
+		{"30569309025904", BrandDinersClub},
+		{"1234567890123458", ""},
+		{"4", BrandVisa},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pan, func(t *testing.T) {
+			if got := BrandOf(tt.pan); got != tt.want {
+				t.Errorf("BrandOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		pan     string
+		wantErr bool
+	}{
+		{name: "visa", pan: "4111111111111111"},
+		{name: "amex", pan: "378282246310005"},
+		{name: "unknown brand", pan: "79927398713"},
+		{name: "check digit", pan: "4111111111111112", wantErr: true},
+		{name: "brand length", pan: "5105105105105100005", wantErr: true},
+		{name: "too short", pan: "4242424", wantErr: true},
+		{name: "not digits", pan: "4111 1111 1111 1111", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.pan); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
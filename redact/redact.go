@@ -0,0 +1,110 @@
+// Package redact masks card data, such as PANs and cardholder names, for
+// logs and other output that must stay PCI compliant.
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/dongri/emv-qrcode/pan"
+)
+
+// Policy tells how values are redacted.
+type Policy int
+
+// const ...
+const (
+	None        Policy = iota // values as is
+	First6Last4               // PANs keep their first 6 and last 4 digits, other values are masked
+	Mask                      // every character is masked
+	Hash                      // values are replaced by a keyed hash, so equal values can be matched
+)
+
+// String ...
+func (p Policy) String() string {
+	switch p {
+	case None:
+		return "none"
+	case First6Last4:
+		return "first6last4"
+	case Mask:
+		return "mask"
+	case Hash:
+		return "hash"
+	}
+	return fmt.Sprintf("Policy(%d)", int(p))
+}
+
+// ParsePolicy parses the name of a policy, as returned by Policy.String.
+func ParsePolicy(name string) (Policy, error) {
+	for _, p := range []Policy{None, First6Last4, Mask, Hash} {
+		if p.String() == name {
+			return p, nil
+		}
+	}
+	return None, fmt.Errorf("redact: policy should be none, first6last4, mask or hash, policy: %s", name)
+}
+
+// MaskCharacter replaces masked characters.
+const MaskCharacter = '*'
+
+// Redactor redacts values with a policy.
+type Redactor struct {
+	Policy Policy
+	// Key is the HMAC-SHA256 key of Hash. It should be secret, as the hashes
+	// of PANs without a key can be brute forced from their BIN and last 4 digits.
+	Key []byte
+}
+
+// Default is the redactor of output masked by default.
+var Default = Redactor{Policy: First6Last4}
+
+// PAN redacts a PAN.
+func (r Redactor) PAN(pan string) string {
+	if r.Policy == First6Last4 && len(pan) > 10 {
+		return pan[:6] + strings.Repeat(string(MaskCharacter), len(pan)-10) + pan[len(pan)-4:]
+	}
+	return r.Value(pan)
+}
+
+// Value redacts a sensitive value other than a PAN, such as a cardholder name.
+func (r Redactor) Value(v string) string {
+	switch r.Policy {
+	case None:
+		return v
+	case Hash:
+		mac := hmac.New(sha256.New, r.Key)
+		mac.Write([]byte(v))
+		return fmt.Sprintf("%X", mac.Sum(nil)[:8])
+	}
+	return strings.Repeat(string(MaskCharacter), len([]rune(v)))
+}
+
+// Text redacts the PANs found in text: runs of 12 to 19 digits that pass the
+// Luhn check.
+func (r Redactor) Text(text string) string {
+	if r.Policy == None {
+		return text
+	}
+	var sb strings.Builder
+	for i := 0; i < len(text); {
+		j := i
+		for j < len(text) && text[j] >= '0' && text[j] <= '9' {
+			j++
+		}
+		if j == i {
+			sb.WriteByte(text[i])
+			i++
+			continue
+		}
+		if run := text[i:j]; len(run) >= 12 && len(run) <= 19 && pan.Luhn(run) {
+			sb.WriteString(r.PAN(run))
+		} else {
+			sb.WriteString(run)
+		}
+		i = j
+	}
+	return sb.String()
+}
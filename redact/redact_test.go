@@ -0,0 +1,87 @@
+package redact
+
+import (
+	"testing"
+)
+
+func TestRedactor(t *testing.T) {
+	key := []byte("secret")
+	tests := []struct {
+		name      string
+		redactor  Redactor
+		wantPAN   string
+		wantValue string
+		wantText  string
+	}{
+		{
+			name:      "none",
+			redactor:  Redactor{},
+			wantPAN:   "4111111111111111",
+			wantValue: "CARDHOLDER/EMV",
+			wantText:  "PAN 4111111111111111, order 123456789012",
+		},
+		{
+			name:      "first 6 last 4",
+			redactor:  Redactor{Policy: First6Last4},
+			wantPAN:   "411111******1111",
+			wantValue: "**************",
+			wantText:  "PAN 411111******1111, order 123456789012",
+		},
+		{
+			name:      "mask",
+			redactor:  Redactor{Policy: Mask},
+			wantPAN:   "****************",
+			wantValue: "**************",
+			wantText:  "PAN ****************, order 123456789012",
+		},
+		{
+			name:      "hash",
+			redactor:  Redactor{Policy: Hash, Key: key},
+			wantPAN:   Redactor{Policy: Hash, Key: key}.Value("4111111111111111"),
+			wantValue: Redactor{Policy: Hash, Key: key}.Value("CARDHOLDER/EMV"),
+			wantText:  "PAN " + Redactor{Policy: Hash, Key: key}.Value("4111111111111111") + ", order 123456789012",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.redactor.PAN("4111111111111111"); got != tt.wantPAN {
+				t.Errorf("Redactor.PAN() = %v, want %v", got, tt.wantPAN)
+			}
+			if got := tt.redactor.Value("CARDHOLDER/EMV"); got != tt.wantValue {
+				t.Errorf("Redactor.Value() = %v, want %v", got, tt.wantValue)
+			}
+			// 123456789012 fails the Luhn check
+			if got := tt.redactor.Text("PAN 4111111111111111, order 123456789012"); got != tt.wantText {
+				t.Errorf("Redactor.Text() = %v, want %v", got, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestRedactor_Hash(t *testing.T) {
+	a := Redactor{Policy: Hash, Key: []byte("a")}
+	b := Redactor{Policy: Hash, Key: []byte("b")}
+	if a.PAN("4111111111111111") != a.PAN("4111111111111111") {
+		t.Errorf("Redactor.PAN() of equal PANs should be equal")
+	}
+	if a.PAN("4111111111111111") == a.PAN("4111111111111112") {
+		t.Errorf("Redactor.PAN() of different PANs should differ")
+	}
+	if a.PAN("4111111111111111") == b.PAN("4111111111111111") {
+		t.Errorf("Redactor.PAN() with different keys should differ")
+	}
+	if got := a.PAN("4111111111111111"); len(got) != 16 {
+		t.Errorf("Redactor.PAN() = %v, want 16 hex digits", got)
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	for _, p := range []Policy{None, First6Last4, Mask, Hash} {
+		if got, err := ParsePolicy(p.String()); err != nil || got != p {
+			t.Errorf("ParsePolicy(%q) = %v, %v, want %v", p.String(), got, err, p)
+		}
+	}
+	if _, err := ParsePolicy("pan"); err == nil {
+		t.Errorf("ParsePolicy() of an unknown policy should fail")
+	}
+}